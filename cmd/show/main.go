@@ -16,7 +16,12 @@ var (
 
 func main() {
 	deps := show.Deps{
-		FileReader: show.OSFileReader{},
+		FileReader: show.StdinFileReader{
+			Stdin: os.Stdin,
+			Files: show.GitFileReader{Files: show.OSFileReader{}},
+		},
+		Stderr: os.Stderr,
+		Git:    show.ExecGit{},
 	}
 	info := cli.BuildInfo{
 		Version: version,
@@ -1,15 +1,21 @@
 module show-cli
 
-go 1.22
+go 1.24
 
 require (
 	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/urfave/cli/v2 v2.27.1
+	golang.org/x/term v0.32.0
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
 	github.com/dlclark/regexp2 v1.11.0 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
@@ -5,13 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 
+	"show-cli/internal/config"
 	"show-cli/internal/show"
 )
 
+// defaultTabs is the tab width used unless --tabs or the config file sets
+// one.
+const defaultTabs = 4
+
+// defaultDiffContext is the number of context lines --diff shows around
+// each change, as in git diff.
+const defaultDiffContext = 3
+
 type BuildInfo struct {
 	Version string
 	Commit  string
@@ -19,35 +32,69 @@ type BuildInfo struct {
 }
 
 type CLI struct {
-	deps   show.Deps
-	info   BuildInfo
-	out    io.Writer
-	errOut io.Writer
+	deps     show.Deps
+	info     BuildInfo
+	out      io.Writer
+	errOut   io.Writer
+	terminal bool
 }
 
 func New(deps show.Deps, info BuildInfo, out io.Writer, errOut io.Writer) *CLI {
-	return &CLI{deps: deps, info: info, out: out, errOut: errOut}
+	if deps.Stderr == nil {
+		deps.Stderr = errOut
+	}
+	return &CLI{deps: deps, info: info, out: out, errOut: errOut, terminal: isTerminal(out)}
+}
+
+// terminalWidth returns the width of w when it is a terminal, then
+// $COLUMNS, or 0 when neither is known.
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// isTerminal reports whether w is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
 }
 
 func (c *CLI) Run(args []string) error {
+	debugEnv, debugDefaults := envDebug()
 	app := &cli.App{
 		Name:            "show",
 		Usage:           "display text file contents with syntax highlighting and line number",
+		ArgsUsage:       "[path...]",
 		Writer:          c.out,
 		ErrWriter:       c.errOut,
 		HideHelp:        true,
 		HideVersion:     true,
 		HideHelpCommand: true,
+		Description:     precedence,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "t",
 				Aliases: []string{"filetype"},
+				EnvVars: envVar("filetype"),
 				Usage:   "force syntax highlighting file type (e.g. go, python, yaml)",
 			},
 			&cli.BoolFlag{
 				Name:    "d",
 				Aliases: []string{"debug"},
-				Usage:   "print debug metadata",
+				EnvVars: debugEnv,
+				Usage:   "print debug metadata (--debug=json writes a JSON record per file to stderr)",
+			},
+			&cli.StringFlag{
+				Name:    "debug-format",
+				EnvVars: envVar("debug-format"),
+				Usage:   "debug output format (text|json, default: text)",
 			},
 			&cli.BoolFlag{
 				Name:    "h",
@@ -60,8 +107,9 @@ func (c *CLI) Run(args []string) error {
 				Usage:   "print version",
 			},
 			&cli.StringFlag{
-				Name:  "theme",
-				Usage: "set syntax highlighting theme (default: onedark, see --list-themes)",
+				Name:    "theme",
+				EnvVars: envVar("theme"),
+				Usage:   "set syntax highlighting theme (default: onedark, see --list-themes)",
 			},
 			&cli.BoolFlag{
 				Name:  "list-file-types",
@@ -71,6 +119,167 @@ func (c *CLI) Run(args []string) error {
 				Name:  "list-themes",
 				Usage: "print supported syntax highlighting themes",
 			},
+			&cli.StringSliceFlag{
+				Name:    "r",
+				Aliases: []string{"line-range"},
+				EnvVars: envVar("line-range"),
+				Usage:   "only print lines START:END (repeatable; open ends like :40 or 500: allowed)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "highlight-line",
+				EnvVars: envVar("highlight-line"),
+				Usage:   "highlight line N or lines N:M (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "map-syntax",
+				EnvVars: envVar("map-syntax"),
+				Usage:   "highlight files matching GLOB as TYPE, e.g. '*.tpl:go' (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-line-numbers",
+				EnvVars: envVar("no-line-numbers"),
+				Usage:   "do not print the line number gutter",
+			},
+			&cli.IntFlag{
+				Name:    "number-start",
+				EnvVars: envVar("number-start"),
+				Usage:   "number the first line N (default: 1)",
+			},
+			&cli.StringFlag{
+				Name:    "separator",
+				EnvVars: envVar("separator"),
+				Usage:   "gutter separator (default: │ in UTF-8 locales, | otherwise)",
+			},
+			&cli.StringFlag{
+				Name:    "gutter-color",
+				EnvVars: envVar("gutter-color"),
+				Usage:   "gutter colour: name, 0-255, #rrggbb or none (default: white)",
+			},
+			&cli.IntFlag{
+				Name:    "gutter-width",
+				EnvVars: envVar("gutter-width"),
+				Usage:   "minimum width of the line number column",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				EnvVars: envVar("output"),
+				Usage:   "output format (terminal|html, default: terminal)",
+			},
+			&cli.BoolFlag{
+				Name:    "html-classes",
+				EnvVars: envVar("html-classes"),
+				Usage:   "use CSS classes and a theme stylesheet in html output",
+			},
+			&cli.BoolFlag{
+				Name:    "html-standalone",
+				EnvVars: envVar("html-standalone"),
+				Usage:   "wrap html output in a complete document",
+			},
+			&cli.StringFlag{
+				Name:    "color",
+				EnvVars: envVar("color"),
+				Usage:   "when to use colours (auto|always|never, default: auto)",
+				Value:   show.ColorAuto,
+			},
+			&cli.StringFlag{
+				Name:    "color-depth",
+				EnvVars: envVar("color-depth"),
+				Usage:   "colour depth (16|256|truecolor, default: detected from COLORTERM and TERM)",
+			},
+			&cli.StringFlag{
+				Name:    "decorations",
+				EnvVars: envVar("decorations"),
+				Usage:   "when to show line numbers and headers (auto|always|never, default: always)",
+			},
+			&cli.BoolFlag{
+				Name:    "p",
+				Aliases: []string{"plain"},
+				EnvVars: envVar("plain"),
+				Usage:   "print plain content without line numbers or headers (same as --decorations=never)",
+			},
+			&cli.StringFlag{
+				Name:    "wrap",
+				EnvVars: envVar("wrap"),
+				Usage:   "wrap long lines (auto|never|character|word, default: auto, i.e. character wrapping on a terminal)",
+			},
+			&cli.IntFlag{
+				Name:    "terminal-width",
+				EnvVars: envVar("terminal-width"),
+				Usage:   "columns to wrap at, including the gutter (default: terminal width, then $COLUMNS)",
+			},
+			&cli.IntFlag{
+				Name:    "tabs",
+				EnvVars: envVar("tabs"),
+				Value:   defaultTabs,
+				Usage:   "expand tabs to this many columns in decorated or coloured output (0 passes tabs through)",
+			},
+			&cli.BoolFlag{
+				Name:    "A",
+				Aliases: []string{"show-all"},
+				EnvVars: envVar("show-all"),
+				Usage:   "show tabs, trailing spaces, line endings and invisible characters as visible glyphs",
+			},
+			&cli.BoolFlag{
+				Name:    "show-line-endings",
+				EnvVars: envVar("show-line-endings"),
+				Usage:   "mark line endings: ␊ for LF, ␍␊ for CRLF, ␍ for CR (implied by --show-all)",
+			},
+			&cli.StringFlag{
+				Name:    "encoding",
+				EnvVars: envVar("encoding"),
+				Usage:   "character encoding of the input (auto|utf-8|utf-16le|utf-16be|utf-32le|utf-32be|iso-8859-1|windows-1252|shift_jis, default: auto)",
+			},
+			&cli.BoolFlag{
+				Name:    "hex",
+				EnvVars: envVar("hex"),
+				Usage:   "show a hexdump with offsets, bytes and printable ASCII (binary files are refused without it)",
+			},
+			&cli.StringFlag{
+				Name:    "rev",
+				EnvVars: envVar("rev"),
+				Usage:   "show files as they were at git revision REV (same as REV:path)",
+			},
+			&cli.StringFlag{
+				Name:    "diff-base",
+				EnvVars: envVar("diff-base"),
+				Usage:   "compare against REV for the git diff markers in the gutter (default: the index)",
+			},
+			&cli.BoolFlag{
+				Name:    "diff",
+				EnvVars: envVar("diff"),
+				Usage:   "only show lines changed against HEAD or --diff-base, with context",
+			},
+			&cli.IntFlag{
+				Name:    "diff-context",
+				EnvVars: envVar("diff-context"),
+				Value:   defaultDiffContext,
+				Usage:   "lines of context around each change with --diff",
+			},
+			&cli.BoolFlag{
+				Name:    "no-git",
+				EnvVars: envVar("no-git"),
+				Usage:   "do not show git diff markers in the gutter",
+			},
+			&cli.StringFlag{
+				Name:    "paging",
+				EnvVars: envVar("paging"),
+				Usage:   "when to page output through $SHOW_PAGER or $PAGER (auto|always|never, default: auto)",
+			},
+			&cli.StringFlag{
+				Name:    "header",
+				EnvVars: envVar("header"),
+				Usage:   "print a file header with path, type and size (auto|always|never, default: auto)",
+			},
+			&cli.StringFlag{
+				Name:    "config",
+				EnvVars: envVar("config"),
+				Usage:   "read defaults from PATH (default: $XDG_CONFIG_HOME/show/config.yaml)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-config",
+				EnvVars: envVar("no-config"),
+				Usage:   "ignore the configuration file",
+			},
 			&cli.StringFlag{
 				Name:  "install-completion",
 				Usage: "print shell completion script (bash|zsh|fish)",
@@ -98,7 +307,13 @@ func (c *CLI) Run(args []string) error {
 		},
 	}
 
-	argv := append([]string{"show"}, normalizeArgs(args)...)
+	defaults, err := envArgs()
+	if err != nil {
+		return err
+	}
+	defaults = append(debugDefaults, defaults...)
+	defaults = unsetEnvArgs(app.Flags, defaults, args)
+	argv := append([]string{"show"}, normalizeArgs(append(defaults, args...))...)
 	return app.Run(argv)
 }
 
@@ -116,26 +331,233 @@ func (c *CLI) runCompletion(shell string) error {
 }
 
 func (c *CLI) runShow(ctx *cli.Context) error {
-	if ctx.NArg() != 1 {
-		return errors.New("usage: show <path>\n-h for help")
+	paths := ctx.Args().Slice()
+	if len(paths) == 0 && c.stdinPiped() {
+		paths = []string{show.StdinPath}
+	}
+	if len(paths) == 0 {
+		return errors.New("usage: show <path>...\n-h for help")
+	}
+
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
 	}
 
-	opts := show.ShowOptions{Path: ctx.Args().Get(0)}
+	opts := show.ShowOptions{}
 	opts.FileType = ctx.String("filetype")
 	if opts.FileType == "" {
 		opts.FileType = ctx.String("t")
 	}
-	opts.Theme = ctx.String("theme")
+	opts.SyntaxMappings, err = syntaxMappings(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	opts.Theme = stringOption(ctx, "theme", cfg.Theme)
 	opts.Debug = ctx.Bool("debug") || ctx.Bool("d")
-	result, err := show.RunShow(context.Background(), c.deps, opts)
+	opts.DebugFormat = ctx.String("debug-format")
+	header, err := headerMode(ctx.String("header"), len(paths))
 	if err != nil {
 		return err
 	}
+	opts.Header = header
+	for _, value := range ctx.StringSlice("line-range") {
+		r, err := show.ParseLineRange(value)
+		if err != nil {
+			return err
+		}
+		opts.LineRanges = append(opts.LineRanges, r)
+	}
+	for _, value := range ctx.StringSlice("highlight-line") {
+		r, err := show.ParseLineRange(value)
+		if err != nil {
+			return err
+		}
+		opts.HighlightLines = append(opts.HighlightLines, r)
+	}
+	opts.Gutter = show.GutterOptions{
+		Hide:      ctx.Bool("no-line-numbers"),
+		Separator: stringOption(ctx, "separator", cfg.Separator),
+		Color:     stringOption(ctx, "gutter-color", cfg.GutterColor),
+		MinWidth:  intOption(ctx, "gutter-width", cfg.GutterWidth),
+	}
+	if !ctx.IsSet("no-line-numbers") && cfg.LineNumbers != nil {
+		opts.Gutter.Hide = !*cfg.LineNumbers
+	}
+	if opts.Gutter.MinWidth < 0 {
+		return fmt.Errorf("invalid gutter width: %d", opts.Gutter.MinWidth)
+	}
+	switch {
+	case ctx.IsSet("number-start"):
+		start := ctx.Int("number-start")
+		opts.Gutter.Start = &start
+	case cfg.NumberStart != nil:
+		opts.Gutter.Start = cfg.NumberStart
+	}
+	if opts.Gutter.Start != nil && *opts.Gutter.Start < 0 {
+		return fmt.Errorf("invalid number start: %d", *opts.Gutter.Start)
+	}
+	opts.Color = stringOption(ctx, "color", cfg.Color)
+	opts.ColorDepth = stringOption(ctx, "color-depth", cfg.ColorDepth)
+	opts.Terminal = c.terminal
+	opts.Decorations = stringOption(ctx, "decorations", cfg.Decorations)
+	if ctx.Bool("plain") {
+		opts.Decorations = show.DecorationsNever
+	}
+	opts.Wrap = stringOption(ctx, "wrap", cfg.Wrap)
+	opts.TerminalWidth = ctx.Int("terminal-width")
+	if opts.TerminalWidth == 0 {
+		opts.TerminalWidth = terminalWidth(c.out)
+	}
+	opts.Tabs = ctx.Int("tabs")
+	if !ctx.IsSet("tabs") && cfg.Tabs != nil {
+		opts.Tabs = *cfg.Tabs
+	}
+	if opts.Tabs < 0 {
+		return fmt.Errorf("invalid tab width: %d", opts.Tabs)
+	}
+	opts.ShowAll = ctx.Bool("show-all")
+	opts.ShowLineEndings = ctx.Bool("show-line-endings")
+	opts.Hex = ctx.Bool("hex")
+	opts.Encoding = ctx.String("encoding")
+	opts.Rev = ctx.String("rev")
+	opts.DiffBase = ctx.String("diff-base")
+	opts.NoGit = ctx.Bool("no-git")
+	opts.Diff = ctx.Bool("diff")
+	opts.DiffContext = ctx.Int("diff-context")
+	if opts.Diff && opts.NoGit {
+		return errors.New("--diff and --no-git cannot be used together")
+	}
+	opts.Output = ctx.String("output")
+	opts.HTML = show.HTMLOptions{
+		Classes:    ctx.Bool("html-classes"),
+		Standalone: ctx.Bool("html-standalone"),
+	}
 
-	_, err = c.out.Write(result.Content)
+	pager, err := c.openPager(stringOption(ctx, "paging", cfg.Paging))
+	if err != nil {
+		return err
+	}
+	err = c.showPaths(opts, paths, pager)
+	// Once the pager has quit, how it exited decides whether showing the
+	// output failed.
+	if closeErr := pager.Close(); err == nil || errors.Is(err, errPagerQuit) {
+		err = closeErr
+	}
 	return err
 }
 
+func (c *CLI) showPaths(opts show.ShowOptions, paths []string, out io.Writer) error {
+	if len(paths) == 1 {
+		opts.Path = paths[0]
+		err := show.RunShowTo(context.Background(), c.deps, opts, out)
+		if errors.Is(err, show.ErrNoChanges) {
+			fmt.Fprintf(c.errOut, "show: %s: %v\n", opts.Path, err)
+			return nil
+		}
+		return err
+	}
+
+	failed := 0
+	for _, result := range show.RunShowFilesTo(context.Background(), c.deps, opts, paths, out) {
+		if result.Err == nil {
+			continue
+		}
+		if errors.Is(result.Err, errPagerQuit) {
+			return result.Err
+		}
+		fmt.Fprintf(c.errOut, "show: %s: %v\n", result.Path, result.Err)
+		if errors.Is(result.Err, show.ErrNoChanges) {
+			continue
+		}
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be shown", failed, len(paths))
+	}
+	return nil
+}
+
+// loadConfig reads the file named by --config, or the default config file
+// when it exists. --no-config skips both.
+func loadConfig(ctx *cli.Context) (config.Config, error) {
+	if ctx.Bool("no-config") {
+		if ctx.IsSet("config") {
+			return config.Config{}, errors.New("--config and --no-config cannot be used together")
+		}
+		return config.Config{}, nil
+	}
+	if ctx.IsSet("config") {
+		return config.Load(ctx.String("config"))
+	}
+	return config.LoadDefault()
+}
+
+// syntaxMappings collects file type mappings in precedence order: flags,
+// $SHOW_FILETYPE_MAP, then the config file. The first match wins.
+func syntaxMappings(ctx *cli.Context, cfg config.Config) ([]show.SyntaxMapping, error) {
+	var mappings []show.SyntaxMapping
+	for _, value := range ctx.StringSlice("map-syntax") {
+		m, err := show.ParseSyntaxMapping(value)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	env, err := envSyntaxMappings()
+	if err != nil {
+		return nil, err
+	}
+	mappings = append(mappings, env...)
+	mappings = append(mappings, cfg.SyntaxMappings...)
+	exts := make([]string, 0, len(cfg.FileTypes))
+	for ext := range cfg.FileTypes {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		mappings = append(mappings, show.ExtensionMapping(ext, cfg.FileTypes[ext]))
+	}
+	return mappings, nil
+}
+
+// stringOption returns the flag when it was given, then the config value,
+// then the flag default.
+func stringOption(ctx *cli.Context, name string, configured string) string {
+	if ctx.IsSet(name) || configured == "" {
+		return ctx.String(name)
+	}
+	return configured
+}
+
+// intOption is stringOption for integer flags.
+func intOption(ctx *cli.Context, name string, configured int) int {
+	if ctx.IsSet(name) || configured == 0 {
+		return ctx.Int(name)
+	}
+	return configured
+}
+
+func headerMode(mode string, paths int) (bool, error) {
+	switch mode {
+	case "", "auto":
+		return paths > 1, nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown header mode: %s (want auto, always or never)", mode)
+	}
+}
+
+// stdinPiped reports whether the file reader can serve show.StdinPath from
+// a pipe, so that a bare `show` reads standard input instead of failing.
+func (c *CLI) stdinPiped() bool {
+	piped, ok := c.deps.FileReader.(interface{ Piped() bool })
+	return ok && piped.Piped()
+}
+
 func normalizeArgs(args []string) []string {
 	if len(args) == 0 {
 		return args
@@ -149,7 +571,11 @@ func normalizeArgs(args []string) []string {
 			positionals = append(positionals, args[i+1:]...)
 			break
 		}
-		if strings.HasPrefix(arg, "-") {
+		if strings.HasPrefix(arg, "-") && arg != show.StdinPath {
+			if format, ok := debugFormatArg(arg); ok {
+				flags = append(flags, "--debug", "--debug-format="+format)
+				continue
+			}
 			flags = append(flags, arg)
 			if flagNeedsValue(arg) && !strings.Contains(arg, "=") && i+1 < len(args) {
 				flags = append(flags, args[i+1])
@@ -162,9 +588,27 @@ func normalizeArgs(args []string) []string {
 	return append(flags, positionals...)
 }
 
+// debugFormatArg splits --debug=FORMAT, which the boolean --debug flag
+// cannot parse itself, into --debug and --debug-format=FORMAT. Boolean
+// values such as --debug=true are left alone.
+func debugFormatArg(arg string) (string, bool) {
+	name, value, ok := strings.Cut(arg, "=")
+	if !ok || (name != "--debug" && name != "-d") {
+		return "", false
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return "", false
+	}
+	return value, true
+}
+
 func flagNeedsValue(arg string) bool {
 	switch arg {
-	case "-t", "--filetype", "--install-completion", "--theme":
+	case "-t", "--filetype", "--install-completion", "--theme", "--header",
+		"-r", "--line-range", "--highlight-line",
+		"--number-start", "--separator", "--gutter-color", "--gutter-width",
+		"--output", "--color", "--color-depth", "--decorations", "--paging",
+		"--config", "--map-syntax", "--debug-format", "--wrap", "--terminal-width", "--tabs", "--encoding", "--rev", "--diff-base", "--diff-context":
 		return true
 	default:
 		return false
@@ -217,7 +661,7 @@ func bashCompletion() string {
 _show() {
   local cur opts
   cur="${COMP_WORDS[COMP_CWORD]}"
-  opts="-h --help -v --version -d --debug -t --filetype --theme --list-file-types --list-themes --install-completion"
+  opts="-h --help -v --version -d --debug --debug-format -t --filetype --theme -r --line-range --highlight-line --map-syntax --no-line-numbers --number-start --separator --gutter-color --gutter-width --output --html-classes --html-standalone --color --color-depth --decorations -p --plain --wrap --terminal-width --tabs -A --show-all --show-line-endings --encoding --hex --rev --diff-base --diff --diff-context --no-git --paging --header --config --no-config --list-file-types --list-themes --install-completion"
   if [[ "$cur" == -* ]]; then
     COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
     return 0
@@ -238,13 +682,48 @@ _arguments \
   '--version[print version]' \
   '-d[print debug metadata]' \
   '--debug[print debug metadata]' \
+  '--debug-format[debug output format]:format:(text json)' \
   '-t[force syntax highlighting file type]:type:' \
   '--filetype[force syntax highlighting file type]:type:' \
   '--theme[set syntax highlighting theme]:theme:' \
+  '*-r[only print a range of lines]:range:' \
+  '*--line-range[only print a range of lines]:range:' \
+  '*--highlight-line[highlight a line or range of lines]:range:' \
+  '*--map-syntax[highlight files matching a glob as a file type]:glob\:type:' \
+  '--no-line-numbers[do not print the line number gutter]' \
+  '--number-start[number the first line N]:number:' \
+  '--separator[gutter separator]:separator:' \
+  '--gutter-color[gutter colour]:color:(none black red green yellow blue magenta cyan white gray)' \
+  '--gutter-width[minimum width of the line number column]:width:' \
+  '--output[output format]:format:(terminal html)' \
+  '--html-classes[use CSS classes in html output]' \
+  '--html-standalone[wrap html output in a complete document]' \
+  '--color[when to use colours]:when:(auto always never)' \
+  '--color-depth[colour depth]:depth:(16 256 truecolor)' \
+  '--decorations[when to show line numbers and headers]:when:(auto always never)' \
+  '-p[print plain content]' \
+  '--plain[print plain content]' \
+  '--wrap[wrap long lines]:mode:(auto never character word)' \
+  '--terminal-width[columns to wrap at]:width:' \
+  '--tabs[expand tabs to this many columns]:width:' \
+  '-A[show invisible characters]' \
+  '--show-all[show invisible characters]' \
+  '--show-line-endings[mark line endings]' \
+  '--encoding[character encoding of the input]:encoding:(auto utf-8 utf-16le utf-16be utf-32le utf-32be iso-8859-1 windows-1252 shift_jis)' \
+  '--hex[show a hexdump]' \
+  '--rev[show files at a git revision]:revision:' \
+  '--diff-base[compare git diff markers against a revision]:revision:' \
+  '--diff[only show changed lines]' \
+  '--diff-context[lines of context around each change]:lines:' \
+  '--no-git[do not show git diff markers]' \
+  '--paging[when to page output]:when:(auto always never)' \
+  '--header[print a file header]:mode:(auto always never)' \
+  '--config[read defaults from a configuration file]:config:_files' \
+  '--no-config[ignore the configuration file]' \
   '--list-file-types[print supported file type aliases]' \
   '--list-themes[print supported syntax highlighting themes]' \
   '--install-completion[print shell completion script (bash|zsh|fish)]:shell:(bash zsh fish)' \
-  '1: :_files'
+  '*: :_files'
 `
 }
 
@@ -256,9 +735,44 @@ complete -c show -s v -d "print version"
 complete -c show -l version -d "print version"
 complete -c show -s d -d "print debug metadata"
 complete -c show -l debug -d "print debug metadata"
+complete -c show -l debug-format -d "debug output format" -xa "text json"
 complete -c show -s t -d "force syntax highlighting file type"
 complete -c show -l filetype -d "force syntax highlighting file type"
 complete -c show -l theme -d "set syntax highlighting theme"
+complete -c show -s r -d "only print a range of lines" -x
+complete -c show -l line-range -d "only print a range of lines" -x
+complete -c show -l highlight-line -d "highlight a line or range of lines" -x
+complete -c show -l map-syntax -d "highlight files matching a glob as a file type" -x
+complete -c show -l no-line-numbers -d "do not print the line number gutter"
+complete -c show -l number-start -d "number the first line N" -x
+complete -c show -l separator -d "gutter separator" -x
+complete -c show -l gutter-color -d "gutter colour" -xa "none black red green yellow blue magenta cyan white gray"
+complete -c show -l gutter-width -d "minimum width of the line number column" -x
+complete -c show -l output -d "output format" -xa "terminal html"
+complete -c show -l html-classes -d "use CSS classes in html output"
+complete -c show -l html-standalone -d "wrap html output in a complete document"
+complete -c show -l color -d "when to use colours" -xa "auto always never"
+complete -c show -l color-depth -d "colour depth" -xa "16 256 truecolor"
+complete -c show -l decorations -d "when to show line numbers and headers" -xa "auto always never"
+complete -c show -s p -d "print plain content"
+complete -c show -l plain -d "print plain content"
+complete -c show -l wrap -d "wrap long lines" -xa "auto never character word"
+complete -c show -l terminal-width -d "columns to wrap at" -x
+complete -c show -l tabs -d "expand tabs to this many columns" -x
+complete -c show -s A -d "show invisible characters"
+complete -c show -l show-all -d "show invisible characters"
+complete -c show -l show-line-endings -d "mark line endings"
+complete -c show -l encoding -d "character encoding of the input" -xa "auto utf-8 utf-16le utf-16be utf-32le utf-32be iso-8859-1 windows-1252 shift_jis"
+complete -c show -l hex -d "show a hexdump"
+complete -c show -l rev -d "show files at a git revision" -x
+complete -c show -l diff-base -d "compare git diff markers against a revision" -x
+complete -c show -l diff -d "only show changed lines"
+complete -c show -l diff-context -d "lines of context around each change" -x
+complete -c show -l no-git -d "do not show git diff markers"
+complete -c show -l paging -d "when to page output" -xa "auto always never"
+complete -c show -l header -d "print a file header" -xa "auto always never"
+complete -c show -l config -d "read defaults from a configuration file" -r -F
+complete -c show -l no-config -d "ignore the configuration file"
 complete -c show -l list-file-types -d "print supported file type aliases"
 complete -c show -l list-themes -d "print supported syntax highlighting themes"
 complete -c show -l install-completion -d "print shell completion script" -xa "bash zsh fish"
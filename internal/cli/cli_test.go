@@ -2,13 +2,36 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"show-cli/internal/show"
 )
 
+// TestMain points the default config path at an empty directory and clears
+// SHOW_* variables so a developer's own settings cannot change test output.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "show-config")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, envPrefix) {
+			os.Unsetenv(name)
+		}
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 type stubFileReader struct {
 	data []byte
 	err  error
@@ -18,6 +41,16 @@ func (s stubFileReader) ReadFile(string) ([]byte, error) {
 	return s.data, s.err
 }
 
+type stubGit struct {
+	diff string
+	rev  *string
+}
+
+func (s stubGit) Diff(_ context.Context, _ string, rev string) ([]byte, error) {
+	*s.rev = rev
+	return []byte(s.diff), nil
+}
+
 func TestRunHelp(t *testing.T) {
 	var out bytes.Buffer
 	var errOut bytes.Buffer
@@ -194,3 +227,718 @@ func TestRunShowTheme(t *testing.T) {
 		t.Fatalf("expected content output, got %q", out.String())
 	}
 }
+
+func TestRunShowStdin(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	for _, args := range [][]string{{"-"}, {}, {"-t", "go"}} {
+		var out bytes.Buffer
+		var errOut bytes.Buffer
+		reader := show.StdinFileReader{Stdin: strings.NewReader("piped\n")}
+		app := New(show.Deps{FileReader: reader}, BuildInfo{}, &out, &errOut)
+
+		if err := app.Run(args); err != nil {
+			t.Fatalf("args %q: expected nil error, got %v", args, err)
+		}
+		if !strings.Contains(out.String(), "1 | ") || !strings.Contains(out.String(), "piped") {
+			t.Fatalf("args %q: expected stdin content, got %q", args, out.String())
+		}
+	}
+}
+
+type pathFileReader map[string]string
+
+func (r pathFileReader) ReadFile(path string) ([]byte, error) {
+	data, ok := r[path]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return []byte(data), nil
+}
+
+func TestRunShowMultipleFiles(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	reader := pathFileReader{"a.go": "package a\n", "b.md": "# title\n"}
+	app := New(show.Deps{FileReader: reader}, BuildInfo{}, &out, &errOut)
+
+	err := app.Run([]string{"a.go", "missing.txt", "b.md"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 files") {
+		t.Fatalf("expected summary error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "show: missing.txt: read file") {
+		t.Fatalf("expected per-file error, got %q", errOut.String())
+	}
+	got := out.String()
+	first := strings.Index(got, "File: a.go")
+	second := strings.Index(got, "File: b.md")
+	if first < 0 || second < first {
+		t.Fatalf("expected headers in order, got %q", got)
+	}
+}
+
+func TestRunShowHeaderMode(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--header", "always", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "File: test.txt") {
+		t.Fatalf("expected header, got %q", out.String())
+	}
+
+	err := app.Run([]string{"--header", "sometimes", "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), "unknown header mode") {
+		t.Fatalf("expected header mode error, got %v", err)
+	}
+}
+
+func TestRunShowLineRange(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("one\ntwo\nthree\nfour\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"test.txt", "-r", "2:3"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "one") || strings.Contains(got, "four") {
+		t.Fatalf("expected lines outside the range to be hidden, got %q", got)
+	}
+	if !strings.Contains(got, "2 | ") || !strings.Contains(got, "three") {
+		t.Fatalf("expected selected lines with original numbers, got %q", got)
+	}
+
+	err := app.Run([]string{"--line-range", "5:1", "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), "invalid line range") {
+		t.Fatalf("expected invalid line range error, got %v", err)
+	}
+}
+
+func TestRunShowHighlightLine(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("one\ntwo\nthree\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--highlight-line", "2:3", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "1 | ") || !strings.Contains(got, "2 > ") || !strings.Contains(got, "3 > ") {
+		t.Fatalf("expected highlighted lines 2 and 3, got %q", got)
+	}
+}
+
+func TestRunShowGutterFlags(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	args := []string{"test.txt", "--number-start", "7", "--separator", "::", "--gutter-width", "3"}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "  7 :: ") {
+		t.Fatalf("expected configured gutter, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"test.txt", "--number-start", "0"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "0 | hello") {
+		t.Fatalf("expected numbering to start at 0, got %q", out.String())
+	}
+
+	err := app.Run([]string{"test.txt", "--number-start", "-1"})
+	if err == nil || !strings.Contains(err.Error(), "invalid number start: -1") {
+		t.Fatalf("expected negative start error, got %v", err)
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--no-line-numbers", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(out.String(), "1 | ") || !strings.Contains(out.String(), "hello") {
+		t.Fatalf("expected content without line numbers, got %q", out.String())
+	}
+}
+
+func TestRunShowHTMLOutput(t *testing.T) {
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("package main\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"main.go", "--output", "html", "--html-standalone"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "<html>") {
+		t.Fatalf("expected standalone html, got %q", out.String())
+	}
+}
+
+func TestRunShowColorFlags(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("package main\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("expected no colour when output is not a terminal, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--color=always", "--color-depth", "truecolor", "main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "\x1b[38;2;") {
+		t.Fatalf("expected truecolor escapes, got %q", out.String())
+	}
+}
+
+func TestRunShowPlainWhenPiped(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	reader := pathFileReader{"a.go": "package a\n", "b.go": "package b"}
+	app := New(show.Deps{FileReader: reader}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--decorations", "auto", "a.go", "b.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "package a\npackage b" {
+		t.Fatalf("expected plain concatenated content, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"-p", "a.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "package a\n" {
+		t.Fatalf("expected plain content, got %q", out.String())
+	}
+}
+
+func writeConfig(t *testing.T, dir string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "show", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunShowConfig(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	writeConfig(t, dir, "number-start: 7\nseparator: \"::\"\ngutter-width: 3\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "  7 :: ") {
+		t.Fatalf("expected gutter from config, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--separator", "|", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "  7 | ") {
+		t.Fatalf("expected flag to override config, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--no-config", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "1 | ") {
+		t.Fatalf("expected defaults with --no-config, got %q", out.String())
+	}
+}
+
+func TestRunShowConfigLineNumbers(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	path := writeConfig(t, t.TempDir(), "line-numbers: false\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--config", path, "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Fatalf("expected config to hide line numbers, got %q", out.String())
+	}
+}
+
+func TestRunShowConfigFileTypes(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	path := writeConfig(t, t.TempDir(), "color: always\nfiletypes:\n  tmpl: go\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("package main\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--config", path, "main.tmpl"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "\x1b[") || strings.Contains(out.String(), "package main") {
+		t.Fatalf("expected mapped go highlighting, got %q", out.String())
+	}
+}
+
+func TestRunShowConfigErrors(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "theme: onedark\npaging: sometimes\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	err := app.Run([]string{"--config", path, "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), path+":2: paging:") {
+		t.Fatalf("expected config error with line, got %v", err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	if err := app.Run([]string{"--config", missing, "test.txt"}); err == nil {
+		t.Fatal("expected error for missing explicit config")
+	}
+
+	if err := app.Run([]string{"--config", path, "--no-config", "test.txt"}); err == nil {
+		t.Fatal("expected error for --config with --no-config")
+	}
+}
+
+func TestRunShowMapSyntax(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	writeConfig(t, dir, "map-syntax:\n  - \"*.tpl:html\"\nfiletypes:\n  tpl: ini\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("{{ .Name }}\n")}}, BuildInfo{}, &out, &errOut)
+
+	run := func(args ...string) string {
+		t.Helper()
+		out.Reset()
+		if err := app.Run(args); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return out.String()
+	}
+
+	if got := run("--debug", "page.tpl"); !strings.Contains(got, "DEBUG file type: HTML") {
+		t.Fatalf("expected config map-syntax before filetypes, got %q", got)
+	}
+	t.Setenv("SHOW_FILETYPE_MAP", "tpl:yaml")
+	if got := run("--debug", "page.tpl"); !strings.Contains(got, "DEBUG file type: YAML") {
+		t.Fatalf("expected SHOW_FILETYPE_MAP to override config, got %q", got)
+	}
+	if got := run("--debug", "--map-syntax", "*.tpl:go", "page.tpl"); !strings.Contains(got, "DEBUG file type: Go") {
+		t.Fatalf("expected --map-syntax to override everything, got %q", got)
+	}
+
+	if err := app.Run([]string{"--map-syntax", "*.tpl", "page.tpl"}); err == nil {
+		t.Fatal("expected error for mapping without a type")
+	}
+}
+
+func TestRunShowDebugJSON(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	reader := pathFileReader{"a.go": "package a\n", "b.py": "print(1)\n"}
+	app := New(show.Deps{FileReader: reader}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"a.go", "--debug=json", "b.py"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(out.String(), "DEBUG") {
+		t.Fatalf("expected no debug lines on stdout, got %q", out.String())
+	}
+	lines := strings.Split(strings.TrimSpace(errOut.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one JSON record per file, got %q", errOut.String())
+	}
+	var record show.DebugRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("expected JSON record, got %q: %v", lines[1], err)
+	}
+	if record.Path != "b.py" || record.Lexer.Name != "Python" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
+
+func TestRunShowDebugJSONMissingFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: pathFileReader{"a.go": "package a\n"}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--debug=json", "missing.go", "a.go"}); err == nil {
+		t.Fatal("expected error for the missing file")
+	}
+	var records []show.DebugRecord
+	for _, line := range strings.Split(strings.TrimSpace(errOut.String()), "\n") {
+		var record show.DebugRecord
+		if json.Unmarshal([]byte(line), &record) == nil {
+			records = append(records, record)
+		}
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected one JSON record per path, got %q", errOut.String())
+	}
+	if records[0].Path != "missing.go" || records[0].Error == "" || records[1].Error != "" {
+		t.Fatalf("expected the error only in the missing file's record, got %+v", records)
+	}
+}
+
+func TestNormalizeArgsDebugFormat(t *testing.T) {
+	tests := map[string][]string{
+		"--debug=json": {"--debug", "--debug-format=json", "a.go"},
+		"-d=text":      {"--debug", "--debug-format=text", "a.go"},
+		"--debug=true": {"--debug=true", "a.go"},
+		"--debug":      {"--debug", "a.go"},
+	}
+	for arg, want := range tests {
+		if got := normalizeArgs([]string{"a.go", arg}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("normalizeArgs(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+func TestRunShowWrap(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("aaa bbb ccc\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--wrap", "word", "--terminal-width", "12", "notes.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 | aaa bbb \n  | ccc\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+
+	t.Setenv("COLUMNS", "10")
+	out.Reset()
+	if err := app.Run([]string{"--wrap=character", "notes.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 | aaa bb\n  | b ccc\n"; out.String() != want {
+		t.Fatalf("expected wrapping at $COLUMNS, got %q", out.String())
+	}
+}
+
+func TestRunShowTabs(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("\tx\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"notes.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 |     x\n"; out.String() != want {
+		t.Fatalf("expected tabs expanded to 4 columns by default, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--tabs", "0", "notes.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 | \tx\n"; out.String() != want {
+		t.Fatalf("expected --tabs 0 to pass tabs through, got %q", out.String())
+	}
+
+	path := writeConfig(t, t.TempDir(), "tabs: 2\n")
+	out.Reset()
+	if err := app.Run([]string{"--config", path, "notes.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 |   x\n"; out.String() != want {
+		t.Fatalf("expected tab width from config, got %q", out.String())
+	}
+
+	if err := app.Run([]string{"--tabs", "-1", "notes.txt"}); err == nil || !strings.Contains(err.Error(), "invalid tab width") {
+		t.Fatalf("expected invalid tab width error, got %v", err)
+	}
+}
+
+func TestRunShowShowAll(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("a,b \r\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"-A", "-p", "data.csv"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "a,b.^M$\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
+
+func TestRunShowLineEndings(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("a \r\nb\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--show-line-endings", "-p", "data.csv"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "a ^M$\nb$\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
+
+func TestRunShowBinary(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("\x7fELF\x00\x00")}}, BuildInfo{}, &out, &errOut)
+
+	err := app.Run([]string{"a.out"})
+	if !errors.Is(err, show.ErrBinary) || !strings.Contains(err.Error(), "--hex") {
+		t.Fatalf("expected binary file error mentioning --hex, got %v", err)
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--hex", "a.out"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "00000000 | 7f 45 4c 46 00 00 ") || !strings.HasSuffix(out.String(), "| .ELF..\n") {
+		t.Fatalf("expected hexdump, got %q", out.String())
+	}
+}
+
+func TestRunShowEncoding(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("name=Andr\xe9\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"-p", "legacy.ini"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "name=André\n"; out.String() != want {
+		t.Fatalf("expected Latin-1 input transcoded, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"-p", "--debug", "--encoding", "cp1252", "legacy.ini"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "DEBUG encoding: windows-1252 (forced)") {
+		t.Fatalf("expected forced encoding in debug output, got %q", out.String())
+	}
+
+	if err := app.Run([]string{"--encoding", "ebcdic", "legacy.ini"}); err == nil || !strings.Contains(err.Error(), "unknown encoding") {
+		t.Fatalf("expected unknown encoding error, got %v", err)
+	}
+}
+
+func TestRunShowGitMarkers(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	var rev string
+	deps := show.Deps{
+		FileReader: stubFileReader{data: []byte("a\nb\n")},
+		Git:        stubGit{diff: "@@ -1,0 +2 @@\n", rev: &rev},
+	}
+	app := New(deps, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--diff-base", "HEAD", "main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1   | a\n2 + | b\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+	if rev != "HEAD" {
+		t.Fatalf("expected diff against HEAD, got %q", rev)
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--no-git", "main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 | a\n2 | b\n"; out.String() != want {
+		t.Fatalf("expected no diff markers with --no-git, got %q", out.String())
+	}
+}
+
+func TestRunShowDiff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	var rev string
+	deps := show.Deps{
+		FileReader: stubFileReader{data: []byte("a\nb\nc\nd\n")},
+		Git:        stubGit{diff: "@@ -4 +4 @@\n", rev: &rev},
+	}
+	app := New(deps, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--diff", "--diff-context", "1", "main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "3   | c\n4 ~ | d\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+	if rev != "HEAD" {
+		t.Fatalf("expected diff against HEAD, got %q", rev)
+	}
+
+	if err := app.Run([]string{"--diff", "--no-git", "main.go"}); err == nil {
+		t.Fatal("expected error for --diff with --no-git")
+	}
+	if err := app.Run([]string{"--diff", "-r", "1:2", "main.go"}); err == nil || !strings.Contains(err.Error(), "line ranges") {
+		t.Fatalf("expected line range error, got %v", err)
+	}
+}
+
+func TestRunShowDiffNoChanges(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	var rev string
+	deps := show.Deps{
+		FileReader: pathFileReader{"a.go": "package a\n", "b.go": "package b\n"},
+		Git:        stubGit{rev: &rev},
+	}
+	app := New(deps, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--diff", "a.go"}); err != nil {
+		t.Fatalf("expected an unchanged file to succeed, got %v", err)
+	}
+	if out.Len() != 0 || errOut.String() != "show: a.go: no changes against HEAD\n" {
+		t.Fatalf("expected only a note on stderr, got %q and %q", out.String(), errOut.String())
+	}
+
+	errOut.Reset()
+	if err := app.Run([]string{"--diff", "--diff-base", "main", "a.go", "b.go"}); err != nil {
+		t.Fatalf("expected unchanged files to succeed, got %v", err)
+	}
+	want := "show: a.go: no changes against main\nshow: b.go: no changes against main\n"
+	if errOut.String() != want {
+		t.Fatalf("expected a note per file, got %q", errOut.String())
+	}
+}
+
+// revisionFileReader serves "REV:path" keys of a pathFileReader as files at
+// a revision.
+type revisionFileReader struct {
+	pathFileReader
+}
+
+func (r revisionFileReader) Revision(path string) (string, string) {
+	rev, file, ok := strings.Cut(path, ":")
+	if !ok {
+		return "", path
+	}
+	return rev, file
+}
+
+func TestRunShowRev(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	reader := revisionFileReader{pathFileReader{"HEAD~1:./main.go": "package main\n"}}
+	app := New(show.Deps{FileReader: reader}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--rev", "HEAD~1", "--header", "always", "main.go"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "HEAD~1:./main.go") || !strings.Contains(out.String(), "1 | package main") {
+		t.Fatalf("expected main.go at HEAD~1, got %q", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"show-cli/internal/config"
+	"show-cli/internal/show"
+)
+
+// envPrefix names the environment variables that supply flag defaults,
+// e.g. SHOW_THEME for --theme.
+const envPrefix = "SHOW_"
+
+// precedence is shown in --help so users can tell where a setting came from.
+const precedence = `Settings are resolved in this order, highest first:
+   1. command-line flags
+   2. arguments in $SHOW_OPTS, e.g. SHOW_OPTS="--theme dracula --paging=never"
+   3. per-flag environment variables such as $SHOW_THEME (shown next to each flag)
+   4. the config file ($XDG_CONFIG_HOME/show/config.yaml or --config)
+   5. built-in defaults
+   A flag given on the command line replaces every value $SHOW_OPTS has
+   for it, including repeatable flags such as --line-range.
+   File type mappings are tried in the same order: --map-syntax, then
+   $SHOW_FILETYPE_MAP (EXT:TYPE pairs separated by commas), then the config
+   file's map-syntax and filetypes, then Chroma's own filename patterns.`
+
+// envVar returns the environment variable for the flag name.
+func envVar(name string) []string {
+	return []string{envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))}
+}
+
+// envDebug returns the environment variables for --debug. SHOW_DEBUG=FORMAT,
+// which the boolean flag cannot parse, is instead returned as a --debug=FORMAT
+// default argument, so it is split like the command-line shorthand and ranks
+// below $SHOW_OPTS.
+func envDebug() ([]string, []string) {
+	vars := envVar("debug")
+	value := os.Getenv(vars[0])
+	if value == "" {
+		return vars, nil
+	}
+	arg := "--debug=" + value
+	if _, ok := debugFormatArg(arg); !ok {
+		return vars, nil
+	}
+	return nil, []string{arg}
+}
+
+// envArgs returns the arguments in $SHOW_OPTS. Only flags and their values
+// are allowed, so a stray path cannot be shown on every run.
+func envArgs() ([]string, error) {
+	value := os.Getenv(envPrefix + "OPTS")
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	args, err := splitArgs(value)
+	if err != nil {
+		return nil, fmt.Errorf("SHOW_OPTS: %w", err)
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == show.StdinPath || arg == "--" {
+			return nil, fmt.Errorf("SHOW_OPTS: unexpected argument %q (only flags are allowed)", arg)
+		}
+		if flagNeedsValue(arg) && !strings.Contains(arg, "=") {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("SHOW_OPTS: flag needs a value: %s", arg)
+			}
+			i++
+		}
+	}
+	return args, nil
+}
+
+// unsetEnvArgs returns the flags in defaults, as returned by envArgs, that
+// args does not set itself. Aliases such as -r and --line-range count as the
+// same flag.
+func unsetEnvArgs(flags []cli.Flag, defaults, args []string) []string {
+	canonical := make(map[string]string)
+	for _, flag := range flags {
+		names := flag.Names()
+		for _, name := range names {
+			canonical[name] = names[0]
+		}
+	}
+	flagNames := func(arg string) []string {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if key, ok := canonical[name]; ok {
+			name = key
+		}
+		if _, ok := debugFormatArg(arg); ok {
+			return []string{name, "debug-format"}
+		}
+		return []string{name}
+	}
+
+	set := make(map[string]bool)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == show.StdinPath {
+			continue
+		}
+		for _, name := range flagNames(arg) {
+			set[name] = true
+		}
+		if flagNeedsValue(arg) && !strings.Contains(arg, "=") {
+			i++
+		}
+	}
+
+	var unset []string
+	for i := 0; i < len(defaults); i++ {
+		end := i + 1
+		if flagNeedsValue(defaults[i]) && !strings.Contains(defaults[i], "=") {
+			end++
+		}
+		if !set[flagNames(defaults[i])[0]] {
+			unset = append(unset, defaults[i:end]...)
+		}
+		i = end - 1
+	}
+	return unset
+}
+
+// splitArgs splits s into words the way a POSIX shell would for simple
+// input: whitespace separates words, quotes group them and a backslash
+// escapes the next character outside single quotes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// envSyntaxMappings parses $SHOW_FILETYPE_MAP, a comma separated list of
+// EXT:TYPE pairs, into extension mappings.
+func envSyntaxMappings() ([]show.SyntaxMapping, error) {
+	value := os.Getenv(envPrefix + "FILETYPE_MAP")
+	var mappings []show.SyntaxMapping
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		ext, fileType, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(ext) == "" || fileType == "" {
+			return nil, fmt.Errorf("SHOW_FILETYPE_MAP: invalid entry %q (want EXT:TYPE)", entry)
+		}
+		if !show.IsSupportedFileType(fileType) {
+			return nil, fmt.Errorf("SHOW_FILETYPE_MAP: unknown file type %q", fileType)
+		}
+		mappings = append(mappings, show.ExtensionMapping(config.NormalizeExtension(ext), fileType))
+	}
+	return mappings, nil
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"show-cli/internal/show"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  --theme dracula\t--paging=never ", []string{"--theme", "dracula", "--paging=never"}},
+		{`--separator ' | '`, []string{"--separator", " | "}},
+		{`--separator "a\"b"`, []string{"--separator", `a"b`}},
+		{`--separator a\ b`, []string{"--separator", "a b"}},
+		{`--separator ''`, []string{"--separator", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.in)
+		if err != nil {
+			t.Fatalf("splitArgs(%q): unexpected error %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{`--theme 'dracula`, `--theme \`} {
+		if _, err := splitArgs(in); err == nil {
+			t.Fatalf("splitArgs(%q): expected error", in)
+		}
+	}
+}
+
+func TestEnvArgs(t *testing.T) {
+	t.Setenv("SHOW_OPTS", "--theme dracula -p")
+	args, err := envArgs()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"--theme", "dracula", "-p"}) {
+		t.Fatalf("unexpected args: %q", args)
+	}
+
+	for _, value := range []string{"--theme dracula main.go", "--theme", "-"} {
+		t.Setenv("SHOW_OPTS", value)
+		if _, err := envArgs(); err == nil || !strings.HasPrefix(err.Error(), "SHOW_OPTS: ") {
+			t.Fatalf("expected SHOW_OPTS error for %q, got %v", value, err)
+		}
+	}
+}
+
+func TestEnvSyntaxMappings(t *testing.T) {
+	t.Setenv("SHOW_FILETYPE_MAP", "TPL:html, .conf:ini")
+	got, err := envSyntaxMappings()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := []show.SyntaxMapping{{Pattern: "*.tpl", FileType: "html"}, {Pattern: "*.conf", FileType: "ini"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for _, value := range []string{"tpl", "tpl:nope", ":go"} {
+		t.Setenv("SHOW_FILETYPE_MAP", value)
+		if _, err := envSyntaxMappings(); err == nil {
+			t.Fatalf("expected error for %q", value)
+		}
+	}
+}
+
+func TestRunShowEnvPrecedence(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	writeConfig(t, dir, "separator: \"cfg\"\nnumber-start: 5\n")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	run := func(args ...string) string {
+		t.Helper()
+		out.Reset()
+		if err := app.Run(args); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return out.String()
+	}
+
+	if got := run("test.txt"); !strings.Contains(got, "5 cfg ") {
+		t.Fatalf("expected config separator, got %q", got)
+	}
+
+	t.Setenv("SHOW_SEPARATOR", "env")
+	if got := run("test.txt"); !strings.Contains(got, "5 env ") {
+		t.Fatalf("expected env to override config, got %q", got)
+	}
+
+	t.Setenv("SHOW_OPTS", "--separator opts")
+	if got := run("test.txt"); !strings.Contains(got, "5 opts ") {
+		t.Fatalf("expected SHOW_OPTS to override env, got %q", got)
+	}
+
+	if got := run("--separator", "flag", "test.txt"); !strings.Contains(got, "5 flag ") {
+		t.Fatalf("expected flag to override SHOW_OPTS, got %q", got)
+	}
+}
+
+func TestRunShowEnvRepeatableFlags(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_OPTS", "-r 1:1 --map-syntax '*.txt:go' --plain")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("one\ntwo\nthree\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "one\n" {
+		t.Fatalf("expected the SHOW_OPTS line range, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"--line-range", "3:3", "--map-syntax", "*.txt:ini", "-d", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "one") || !strings.Contains(got, "three") {
+		t.Fatalf("expected only the command line range, got %q", got)
+	}
+	if !strings.Contains(got, "file type: INI") {
+		t.Fatalf("expected the command line syntax mapping, got %q", got)
+	}
+}
+
+func TestRunShowEnvBool(t *testing.T) {
+	t.Setenv("SHOW_PLAIN", "true")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Fatalf("expected plain output from SHOW_PLAIN, got %q", out.String())
+	}
+}
+
+func TestRunShowEnvDebugFormat(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_DEBUG", "json")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(out.String(), "DEBUG") || !strings.HasPrefix(errOut.String(), "{") {
+		t.Fatalf("expected a JSON debug record on stderr, got %q and %q", out.String(), errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if err := app.Run([]string{"--debug-format=text", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "DEBUG file type") || errOut.Len() != 0 {
+		t.Fatalf("expected --debug-format to override SHOW_DEBUG, got %q and %q", out.String(), errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if err := app.Run([]string{"--debug=false", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(out.String(), "DEBUG") || errOut.Len() != 0 {
+		t.Fatalf("expected --debug=false to override SHOW_DEBUG, got %q and %q", out.String(), errOut.String())
+	}
+
+	t.Setenv("SHOW_DEBUG", "true")
+	out.Reset()
+	if err := app.Run([]string{"test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "DEBUG file type") {
+		t.Fatalf("expected boolean SHOW_DEBUG to enable text debug output, got %q", out.String())
+	}
+}
+
+func TestRunHelpPrecedence(t *testing.T) {
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("ok")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--help"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, want := range []string{"SHOW_OPTS", "[$SHOW_THEME]", "config file"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected help to mention %q, got %q", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+const defaultPager = "less -R -F -X"
+
+// errPagerQuit stops rendering once the user has quit the pager.
+var errPagerQuit = errors.New("pager quit")
+
+// pagerWriter forwards output to out, or through a pager once more than
+// threshold lines have been written. A negative threshold never pages and
+// zero pages immediately.
+type pagerWriter struct {
+	out       io.Writer
+	errOut    io.Writer
+	command   []string
+	threshold int
+	lines     int
+	buf       bytes.Buffer
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+}
+
+// openPager sets up paging for mode (auto, always or never). The pager
+// command is only read when paging can happen, so a bad $PAGER does not
+// break --paging=never or output that is not going to a terminal.
+func (c *CLI) openPager(mode string) (*pagerWriter, error) {
+	p := &pagerWriter{out: c.out, errOut: c.errOut, threshold: -1}
+	switch mode {
+	case "", "auto":
+		if c.terminal {
+			p.threshold = terminalHeight(c.out) - 1
+		}
+	case "always":
+		p.threshold = 0
+	case "never":
+	default:
+		return nil, fmt.Errorf("unknown paging mode: %s (want auto, always or never)", mode)
+	}
+	if p.threshold < 0 {
+		return p, nil
+	}
+	command, err := pagerCommand()
+	if err != nil {
+		return nil, err
+	}
+	p.command = command
+	if len(p.command) == 0 {
+		p.threshold = -1
+	}
+	if p.threshold == 0 {
+		if err := p.start(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// pagerCommand returns $SHOW_PAGER, $PAGER or the default pager, split into
+// arguments like a shell would. An empty variable disables paging.
+func pagerCommand() ([]string, error) {
+	for _, key := range []string{"SHOW_PAGER", "PAGER"} {
+		if value, ok := os.LookupEnv(key); ok {
+			args, err := splitArgs(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			return args, nil
+		}
+	}
+	return splitArgs(defaultPager)
+}
+
+func terminalHeight(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return -1
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || height <= 0 {
+		return -1
+	}
+	return height
+}
+
+func (p *pagerWriter) Write(b []byte) (int, error) {
+	if p.stdin != nil {
+		return p.writePager(b)
+	}
+	if p.threshold < 0 {
+		return p.out.Write(b)
+	}
+	p.buf.Write(b)
+	p.lines += bytes.Count(b, []byte("\n"))
+	if p.lines <= p.threshold {
+		return len(b), nil
+	}
+	if err := p.start(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (p *pagerWriter) start() error {
+	cmd := exec.Command(p.command[0], p.command[1:]...)
+	cmd.Stdout = p.out
+	cmd.Stderr = p.errOut
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("start pager: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			// Without a pager installed, write straight to the output.
+			p.threshold = -1
+			_, err := p.out.Write(p.buf.Bytes())
+			p.buf.Reset()
+			return err
+		}
+		return fmt.Errorf("start pager: %w", err)
+	}
+	p.cmd = cmd
+	p.stdin = stdin
+	if p.buf.Len() > 0 {
+		buffered := p.buf.Bytes()
+		p.buf.Reset()
+		if _, err := p.writePager(buffered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePager writes to the pager, turning a broken pipe (the user quit
+// early) into errPagerQuit.
+func (p *pagerWriter) writePager(b []byte) (int, error) {
+	n, err := p.stdin.Write(b)
+	if err != nil && (errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)) {
+		return n, errPagerQuit
+	}
+	return n, err
+}
+
+// Close flushes output that never reached the threshold, or closes the
+// pager's input and waits for the user to quit it.
+func (p *pagerWriter) Close() error {
+	if p.cmd == nil {
+		if p.buf.Len() == 0 {
+			return nil
+		}
+		_, err := p.out.Write(p.buf.Bytes())
+		p.buf.Reset()
+		return err
+	}
+	closeErr := p.stdin.Close()
+	if err := p.cmd.Wait(); err != nil && !killedByPipe(err) {
+		return fmt.Errorf("pager %s: %w", p.command[0], err)
+	}
+	if closeErr != nil && !errors.Is(closeErr, syscall.EPIPE) && !errors.Is(closeErr, os.ErrClosed) {
+		return closeErr
+	}
+	return nil
+}
+
+// killedByPipe reports whether a pager exited on SIGPIPE, which happens when
+// the user quits it while it is still writing to a pipe of its own.
+func killedByPipe(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGPIPE
+}
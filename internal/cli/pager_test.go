@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"show-cli/internal/show"
+)
+
+func writePagerScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pager.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("write pager script: %v", err)
+	}
+	return path
+}
+
+func TestRunShowPagingAlways(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_PAGER", writePagerScript(t, `echo "paged by stub"; cat`))
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--paging", "always", "test.txt"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, "paged by stub\n") || !strings.Contains(got, "hello") {
+		t.Fatalf("expected content piped through the pager, got %q", got)
+	}
+}
+
+func TestRunShowPagerQuitsEarly(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_PAGER", writePagerScript(t, `exit 0`))
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	data := []byte(strings.Repeat("a long line of text that fills the pipe\n", 50000))
+	app := New(show.Deps{FileReader: stubFileReader{data: data}}, BuildInfo{}, &out, &errOut)
+
+	if err := app.Run([]string{"--paging=always", "test.txt"}); err != nil {
+		t.Fatalf("expected quitting the pager to succeed, got %v", err)
+	}
+}
+
+func TestRunShowPagingNever(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_PAGER", writePagerScript(t, `echo "paged by stub"; cat`))
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	for _, mode := range []string{"never", "auto"} {
+		out.Reset()
+		if err := app.Run([]string{"--paging", mode, "test.txt"}); err != nil {
+			t.Fatalf("%s: expected nil error, got %v", mode, err)
+		}
+		if strings.Contains(out.String(), "paged by stub") || !strings.Contains(out.String(), "hello") {
+			t.Fatalf("%s: expected direct output, got %q", mode, out.String())
+		}
+	}
+
+	err := app.Run([]string{"--paging", "sometimes", "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), "unknown paging mode") {
+		t.Fatalf("expected unknown paging mode error, got %v", err)
+	}
+}
+
+func TestRunShowPagingNeverBadPager(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("SHOW_PAGER", "")
+	os.Unsetenv("SHOW_PAGER")
+	t.Setenv("PAGER", `less '`)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	for _, mode := range []string{"never", "auto"} {
+		out.Reset()
+		if err := app.Run([]string{"--paging", mode, "test.txt"}); err != nil {
+			t.Fatalf("%s: expected a bad PAGER to be ignored, got %v", mode, err)
+		}
+		if !strings.Contains(out.String(), "hello") {
+			t.Fatalf("%s: expected direct output, got %q", mode, out.String())
+		}
+	}
+
+	err := app.Run([]string{"--paging", "always", "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), "PAGER") {
+		t.Fatalf("expected unterminated quote error with --paging=always, got %v", err)
+	}
+}
+
+func TestPagerWriterThreshold(t *testing.T) {
+	t.Setenv("SHOW_PAGER", writePagerScript(t, `echo "paged by stub"; cat`))
+
+	var out bytes.Buffer
+	command, err := pagerCommand()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	p := &pagerWriter{out: &out, errOut: &out, command: command, threshold: 2}
+	if _, err := p.Write([]byte("one\ntwo\n")); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if p.cmd != nil || out.Len() != 0 {
+		t.Fatalf("expected output within the threshold to be held back, got %q", out.String())
+	}
+	if _, err := p.Write([]byte("three\n")); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if out.String() != "paged by stub\none\ntwo\nthree\n" {
+		t.Fatalf("expected pager to receive all output, got %q", out.String())
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("SHOW_PAGER", "")
+	t.Setenv("PAGER", "more")
+	if got, err := pagerCommand(); err != nil || len(got) != 0 {
+		t.Fatalf("expected empty SHOW_PAGER to disable paging, got %q, %v", got, err)
+	}
+
+	os.Unsetenv("SHOW_PAGER")
+	if got, err := pagerCommand(); err != nil || len(got) != 1 || got[0] != "more" {
+		t.Fatalf("expected $PAGER, got %q, %v", got, err)
+	}
+
+	t.Setenv("PAGER", `'/opt/my pager/less' -R`)
+	if got, err := pagerCommand(); err != nil || len(got) != 2 || got[0] != "/opt/my pager/less" {
+		t.Fatalf("expected quoted pager path, got %q, %v", got, err)
+	}
+
+	t.Setenv("PAGER", `less "-R`)
+	if _, err := pagerCommand(); err == nil || !strings.Contains(err.Error(), "PAGER") {
+		t.Fatalf("expected unterminated quote error, got %v", err)
+	}
+
+	os.Unsetenv("PAGER")
+	if got, err := pagerCommand(); err != nil || strings.Join(got, " ") != defaultPager {
+		t.Fatalf("expected default pager, got %q, %v", got, err)
+	}
+}
+
+func TestRunShowPagerExitStatus(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	app := New(show.Deps{FileReader: stubFileReader{data: []byte("hello\n")}}, BuildInfo{}, &out, &errOut)
+
+	t.Setenv("SHOW_PAGER", "false")
+	err := app.Run([]string{"--paging=always", "test.txt"})
+	if err == nil || !strings.Contains(err.Error(), "pager false: exit status 1") {
+		t.Fatalf("expected failing pager to be reported, got %v", err)
+	}
+
+	t.Setenv("SHOW_PAGER", writePagerScript(t, `kill -PIPE $$`))
+	if err := app.Run([]string{"--paging=always", "test.txt"}); err != nil {
+		t.Fatalf("expected a pager killed by SIGPIPE to be ignored, got %v", err)
+	}
+
+	out.Reset()
+	t.Setenv("SHOW_PAGER", filepath.Join(t.TempDir(), "no-such-pager"))
+	if err := app.Run([]string{"--paging=always", "test.txt"}); err != nil {
+		t.Fatalf("expected a missing pager to be skipped, got %v", err)
+	}
+	if !strings.Contains(out.String(), "hello") {
+		t.Fatalf("expected output without the pager, got %q", out.String())
+	}
+
+	out.Reset()
+	t.Setenv("SHOW_PAGER", "no-such-pager-on-path")
+	if err := app.Run([]string{"--paging=always", "test.txt"}); err != nil || !strings.Contains(out.String(), "hello") {
+		t.Fatalf("expected output without the pager, got %q, %v", out.String(), err)
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"show-cli/internal/show"
+)
+
+// Config holds defaults loaded from config.yaml. Empty fields and nil
+// pointers mean the key was not set, so command-line flags and built-in
+// defaults apply.
+type Config struct {
+	Path        string
+	Theme       string
+	LineNumbers *bool
+	// NumberStart is nil when the file does not set it, since zero is a
+	// valid first line number.
+	NumberStart *int
+	Separator   string
+	GutterColor string
+	GutterWidth int
+	Paging      string
+	Color       string
+	ColorDepth  string
+	Decorations string
+	Wrap        string
+	// Tabs is nil when the file does not set a tab width, since zero
+	// passes tabs through.
+	Tabs *int
+	// FileTypes maps a lowercase file extension, including its leading
+	// dot, to a file type alias.
+	FileTypes map[string]string
+	// SyntaxMappings are the map-syntax entries, in file order.
+	SyntaxMappings []show.SyntaxMapping
+}
+
+// Error is a config problem with the file and line it was found on.
+type Error struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("config %s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("config %s: %v", e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// entryError places an error on the line of a nested entry rather than on
+// the line where its key's value starts.
+type entryError struct {
+	line int
+	err  error
+}
+
+func (e *entryError) Error() string {
+	return e.err.Error()
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/show/config.yaml, falling back to
+// ~/.config/show/config.yaml.
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "show", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "show", "config.yaml")
+}
+
+// LoadDefault loads the config at DefaultPath. A missing file is not an
+// error and yields an empty Config.
+func LoadDefault() (Config, error) {
+	path := DefaultPath()
+	if path == "" {
+		return Config{}, nil
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	return cfg, err
+}
+
+// Load reads and validates the config at path.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return Config{}, &Error{Path: path, Err: err}
+	}
+	return Parse(path, data)
+}
+
+// Parse validates data as a config file. path is only used in errors.
+func Parse(path string, data []byte) (Config, error) {
+	cfg := Config{Path: path}
+	var doc yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return cfg, nil
+		}
+		return Config{}, &Error{Path: path, Err: errors.New(strings.TrimPrefix(err.Error(), "yaml: "))}
+	}
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return Config{}, &Error{Path: path, Line: root.Line, Err: errors.New("expected a mapping of settings")}
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if seen[key.Value] {
+			return Config{}, &Error{Path: path, Line: key.Line, Err: fmt.Errorf("duplicate key %q", key.Value)}
+		}
+		seen[key.Value] = true
+		if err := cfg.set(key.Value, value); err != nil {
+			line := value.Line
+			var entry *entryError
+			if errors.As(err, &entry) {
+				line, err = entry.line, entry.err
+			}
+			return Config{}, &Error{Path: path, Line: line, Err: fmt.Errorf("%s: %w", key.Value, err)}
+		}
+	}
+	return cfg, nil
+}
+
+func (c *Config) set(key string, value *yaml.Node) error {
+	switch key {
+	case "theme":
+		if err := decodeScalar(value, &c.Theme); err != nil {
+			return err
+		}
+		if !show.IsSupportedTheme(c.Theme) {
+			return fmt.Errorf("unknown theme %q", c.Theme)
+		}
+	case "line-numbers":
+		var enabled bool
+		if err := decodeScalar(value, &enabled); err != nil {
+			return err
+		}
+		c.LineNumbers = &enabled
+	case "number-start":
+		var start int
+		if err := decodeScalar(value, &start); err != nil {
+			return err
+		}
+		if start < 0 {
+			return errors.New("must not be negative")
+		}
+		c.NumberStart = &start
+	case "separator":
+		return decodeScalar(value, &c.Separator)
+	case "gutter-color":
+		return decodeScalar(value, &c.GutterColor)
+	case "gutter-width":
+		if err := decodeScalar(value, &c.GutterWidth); err != nil {
+			return err
+		}
+		if c.GutterWidth < 0 {
+			return errors.New("must not be negative")
+		}
+	case "paging":
+		return decodeChoice(value, &c.Paging, "auto", "always", "never")
+	case "color":
+		return decodeChoice(value, &c.Color, show.ColorAuto, show.ColorAlways, show.ColorNever)
+	case "color-depth":
+		return decodeChoice(value, &c.ColorDepth, show.Depth16, show.Depth256, show.DepthTrueColor)
+	case "decorations":
+		return decodeChoice(value, &c.Decorations, show.DecorationsAuto, show.DecorationsAlways, show.DecorationsNever)
+	case "wrap":
+		return decodeChoice(value, &c.Wrap, show.WrapAuto, show.WrapNever, show.WrapCharacter, show.WrapWord)
+	case "tabs":
+		var width int
+		if err := decodeScalar(value, &width); err != nil {
+			return err
+		}
+		if width < 0 {
+			return errors.New("must not be negative")
+		}
+		c.Tabs = &width
+	case "filetypes":
+		return c.setFileTypes(value)
+	case "map-syntax":
+		return c.setSyntaxMappings(value)
+	default:
+		return errors.New("unknown key")
+	}
+	return nil
+}
+
+func (c *Config) setFileTypes(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return errors.New("expected a mapping of extension to file type")
+	}
+	c.FileTypes = make(map[string]string)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		ext, fileType := value.Content[i], value.Content[i+1]
+		var name string
+		if err := decodeScalar(fileType, &name); err != nil {
+			return &entryError{line: fileType.Line, err: fmt.Errorf("%s: %w", ext.Value, err)}
+		}
+		if !show.IsSupportedFileType(name) {
+			return &entryError{line: fileType.Line, err: fmt.Errorf("%s: unknown file type %q", ext.Value, name)}
+		}
+		c.FileTypes[NormalizeExtension(ext.Value)] = name
+	}
+	return nil
+}
+
+func (c *Config) setSyntaxMappings(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		return errors.New("expected a list of GLOB:TYPE entries")
+	}
+	c.SyntaxMappings = nil
+	for _, entry := range value.Content {
+		var text string
+		if err := decodeScalar(entry, &text); err != nil {
+			return &entryError{line: entry.Line, err: err}
+		}
+		m, err := show.ParseSyntaxMapping(text)
+		if err != nil {
+			return &entryError{line: entry.Line, err: err}
+		}
+		c.SyntaxMappings = append(c.SyntaxMappings, m)
+	}
+	return nil
+}
+
+// NormalizeExtension lowercases ext and gives it a leading dot.
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func decodeScalar(value *yaml.Node, target any) error {
+	if value.Kind != yaml.ScalarNode {
+		return errors.New("expected a single value")
+	}
+	if err := value.Decode(target); err != nil {
+		return fmt.Errorf("invalid value %q", value.Value)
+	}
+	return nil
+}
+
+func decodeChoice(value *yaml.Node, target *string, choices ...string) error {
+	if err := decodeScalar(value, target); err != nil {
+		return err
+	}
+	for _, choice := range choices {
+		if *target == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q (want %s)", *target, strings.Join(choices, ", "))
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"show-cli/internal/show"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`theme: monokai
+line-numbers: false
+number-start: 10
+separator: "::"
+gutter-width: 4
+paging: never
+color: always
+color-depth: "256"
+decorations: auto
+wrap: word
+tabs: 8
+filetypes:
+  TPL: html
+  .conf: ini
+map-syntax:
+  - "Jenkinsfile.*:groovy"
+  - "*.conf.j2:jinja"
+`)
+	cfg, err := Parse("config.yaml", data)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if cfg.Theme != "monokai" || cfg.NumberStart == nil || *cfg.NumberStart != 10 || cfg.Separator != "::" || cfg.GutterWidth != 4 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.LineNumbers == nil || *cfg.LineNumbers {
+		t.Fatalf("expected line numbers disabled, got %v", cfg.LineNumbers)
+	}
+	if cfg.Paging != "never" || cfg.Color != "always" || cfg.ColorDepth != "256" || cfg.Decorations != "auto" || cfg.Wrap != "word" {
+		t.Fatalf("unexpected modes: %+v", cfg)
+	}
+	if cfg.Tabs == nil || *cfg.Tabs != 8 {
+		t.Fatalf("expected tabs 8, got %v", cfg.Tabs)
+	}
+	if cfg.FileTypes[".tpl"] != "html" || cfg.FileTypes[".conf"] != "ini" {
+		t.Fatalf("unexpected file types: %v", cfg.FileTypes)
+	}
+	want := []show.SyntaxMapping{{Pattern: "Jenkinsfile.*", FileType: "groovy"}, {Pattern: "*.conf.j2", FileType: "jinja"}}
+	if !reflect.DeepEqual(cfg.SyntaxMappings, want) {
+		t.Fatalf("expected syntax mappings %v, got %v", want, cfg.SyntaxMappings)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	cfg, err := Parse("config.yaml", nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if cfg.Theme != "" || cfg.LineNumbers != nil || cfg.FileTypes != nil {
+		t.Fatalf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"theme: monokai\ncolour: always\n", "config config.yaml:2: colour: unknown key"},
+		{"theme: nope\n", `config config.yaml:1: theme: unknown theme "nope"`},
+		{"paging: sometimes\n", `config config.yaml:1: paging: invalid value "sometimes" (want auto, always, never)`},
+		{"number-start: ten\n", `config config.yaml:1: number-start: invalid value "ten"`},
+		{"number-start: -1\n", "config config.yaml:1: number-start: must not be negative"},
+		{"gutter-width: -1\n", "config config.yaml:1: gutter-width: must not be negative"},
+		{"tabs: -2\n", "config config.yaml:1: tabs: must not be negative"},
+		{"filetypes:\n  tpl: nope\n", `config config.yaml:2: filetypes: tpl: unknown file type "nope"`},
+		{"map-syntax: \"*.tpl:go\"\n", "config config.yaml:1: map-syntax: expected a list of GLOB:TYPE entries"},
+		{"map-syntax:\n  - \"*.tpl:go\"\n  - \"*.tpl\"\n", `config config.yaml:3: map-syntax: invalid syntax mapping "*.tpl" (want GLOB:TYPE)`},
+		{"theme: monokai\ntheme: dracula\n", `config config.yaml:2: duplicate key "theme"`},
+		{"- theme\n", "config config.yaml:1: expected a mapping of settings"},
+		{"theme: [\n", "config config.yaml: line 1: did not find expected node content"},
+	}
+	for _, tt := range tests {
+		_, err := Parse("config.yaml", []byte(tt.data))
+		if err == nil {
+			t.Fatalf("expected error for %q", tt.data)
+		}
+		if err.Error() != tt.want {
+			t.Fatalf("expected %q, got %q", tt.want, err.Error())
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	_, err := Load(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "config "+path+": ") {
+		t.Fatalf("expected path in error, got %q", err.Error())
+	}
+}
+
+func TestLoadDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := LoadDefault()
+	if err != nil {
+		t.Fatalf("expected missing default config to be ignored, got %v", err)
+	}
+	if cfg.Theme != "" {
+		t.Fatalf("expected empty config, got %+v", cfg)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "show"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "show", "config.yaml"), []byte("theme: monokai\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadDefault()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if cfg.Theme != "monokai" {
+		t.Fatalf("expected theme from default config, got %+v", cfg)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	if got := DefaultPath(); got != "/home/user/.config/show/config.yaml" {
+		t.Fatalf("unexpected default path: %s", got)
+	}
+}
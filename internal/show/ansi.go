@@ -0,0 +1,33 @@
+package show
+
+// leadingEscapes returns the prefix of s made up of complete ANSI CSI
+// escape sequences (ESC '[' ... final byte).
+func leadingEscapes(s string) string {
+	i := 0
+	for {
+		n := escapeLen(s[i:])
+		if n == 0 {
+			return s[:i]
+		}
+		i += n
+	}
+}
+
+// escapeLen returns the length of the CSI escape sequence at the start of
+// s, or 0 when s does not start with a complete one.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// isReset reports whether the escape sequence esc resets all attributes.
+func isReset(esc string) bool {
+	return esc == "\x1b[0m" || esc == "\x1b[m"
+}
@@ -0,0 +1,85 @@
+package show
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// CanceledError reports that rendering stopped because its context was
+// canceled or its deadline passed. Err is the context's error, so
+// errors.Is(err, context.Canceled) and context.DeadlineExceeded work.
+type CanceledError struct {
+	Stage string
+	Err   error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+func canceled(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		return &CanceledError{Stage: stage, Err: err}
+	}
+	return nil
+}
+
+// readFileContext runs ReadFile in the background so that a slow reader
+// cannot outlive ctx. The read itself is abandoned, not interrupted.
+func readFileContext(ctx context.Context, reader FileReader, path string) ([]byte, error) {
+	if err := canceled(ctx, "read file"); err != nil {
+		return nil, err
+	}
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := reader.ReadFile(path)
+		done <- result{data: data, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, canceled(ctx, "read file")
+	case r := <-done:
+		return r.data, r.err
+	}
+}
+
+// contextReader fails reads once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := canceled(c.ctx, "read file"); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// contextIterator ends token iteration once ctx is done and records why in
+// *err, so the formatter stops early and the caller can report it.
+func contextIterator(ctx context.Context, it chroma.Iterator, err *error) chroma.Iterator {
+	const checkEvery = 256
+	n := 0
+	return func() chroma.Token {
+		n++
+		if n%checkEvery == 0 {
+			if cerr := canceled(ctx, "highlight content"); cerr != nil {
+				*err = cerr
+				return chroma.EOF
+			}
+		}
+		return it()
+	}
+}
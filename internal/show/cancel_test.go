@@ -0,0 +1,120 @@
+package show
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// slowReader blocks ReadFile until release is closed.
+type slowReader struct {
+	release chan struct{}
+}
+
+func (s slowReader) ReadFile(string) ([]byte, error) {
+	<-s.release
+	return []byte("late\n"), nil
+}
+
+// cancelingReader cancels its context once the first read has been served.
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c cancelingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.cancel()
+	return n, err
+}
+
+type cancelingOpener struct {
+	data   string
+	cancel context.CancelFunc
+}
+
+func (c cancelingOpener) ReadFile(string) ([]byte, error) {
+	return []byte(c.data), nil
+}
+
+func (c cancelingOpener) Open(string) (io.ReadCloser, error) {
+	return io.NopCloser(cancelingReader{r: strings.NewReader(c.data), cancel: c.cancel}), nil
+}
+
+func TestRunShowSlowReaderDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := RunShow(ctx, Deps{FileReader: slowReader{release: release}}, ShowOptions{Path: "slow.txt"})
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected RunShow to return promptly, took %v", time.Since(start))
+	}
+	var cerr *CanceledError
+	if !errors.As(err, &cerr) || cerr.Stage != "read file" {
+		t.Fatalf("expected read file CanceledError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRunShowAlreadyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := RunShow(ctx, Deps{FileReader: stubReader{data: []byte("ok\n")}}, ShowOptions{Path: "file.txt"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestRunShowToCanceledMidStream(t *testing.T) {
+	setChunkSize(t, 16)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var out strings.Builder
+	deps := Deps{FileReader: cancelingOpener{data: strings.Repeat("line\n", 100), cancel: cancel}}
+	err := RunShowTo(ctx, deps, ShowOptions{Path: "file.txt"}, &out)
+
+	var cerr *CanceledError
+	if !errors.As(err, &cerr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected CanceledError, got %v", err)
+	}
+	if strings.Count(out.String(), "line") >= 100 {
+		t.Fatalf("expected output to stop early, got %d lines", strings.Count(out.String(), "line"))
+	}
+}
+
+func TestContextIterator(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	iterator, err := lexers.Get("go").Tokenise(nil, strings.Repeat("x := 1\n", 500))
+	if err != nil {
+		t.Fatalf("tokenise: %v", err)
+	}
+	var cancelErr error
+	iterator = contextIterator(ctx, iterator, &cancelErr)
+	count := 0
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		count++
+	}
+	if count >= 500 {
+		t.Fatalf("expected iteration to stop early, got %d tokens", count)
+	}
+	if !errors.Is(cancelErr, context.Canceled) {
+		t.Fatalf("expected recorded cancellation, got %v", cancelErr)
+	}
+}
@@ -0,0 +1,158 @@
+package show
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// Colour modes accepted by ShowOptions.Color. The zero value colours output
+// unless NO_COLOR is set.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Colour depths accepted by ShowOptions.ColorDepth. The zero value detects
+// the depth from COLORTERM and TERM.
+const (
+	Depth16        = "16"
+	Depth256       = "256"
+	DepthTrueColor = "truecolor"
+)
+
+func validateColor(mode string, depth string) error {
+	switch mode {
+	case "", ColorAuto, ColorAlways, ColorNever:
+	default:
+		return fmt.Errorf("unknown color mode: %s (want auto, always or never)", mode)
+	}
+	switch depth {
+	case "", Depth16, Depth256, DepthTrueColor:
+	default:
+		return fmt.Errorf("unknown color depth: %s (want 16, 256 or truecolor)", depth)
+	}
+	return nil
+}
+
+// useColor decides whether output gets ANSI colour. ColorAuto requires an
+// interactive terminal that is not TERM=dumb; both ColorAuto and the zero
+// value honour NO_COLOR, while ColorAlways overrides it.
+func useColor(mode string, terminal bool) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+		return terminal && !noColor() && os.Getenv("TERM") != "dumb"
+	default:
+		return !noColor()
+	}
+}
+
+// colorDepth returns depth, or the depth advertised by the environment
+// when depth is empty.
+func colorDepth(depth string) string {
+	if depth != "" {
+		return depth
+	}
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return DepthTrueColor
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "direct"):
+		return DepthTrueColor
+	case strings.Contains(term, "256color"):
+		return Depth256
+	default:
+		return Depth16
+	}
+}
+
+// formatterName maps a colour depth to a Chroma formatter.
+func formatterName(depth string, color bool) string {
+	if !color {
+		return "noop"
+	}
+	switch depth {
+	case Depth16:
+		return "terminal16"
+	case Depth256:
+		return "terminal256"
+	default:
+		return "terminal16m"
+	}
+}
+
+// foregroundEscape and backgroundEscape render colour at depth.
+func foregroundEscape(colour chroma.Colour, depth string) string {
+	return colourEscape(colour, depth, 38, 30)
+}
+
+func backgroundEscape(colour chroma.Colour, depth string) string {
+	return colourEscape(colour, depth, 48, 40)
+}
+
+func colourEscape(colour chroma.Colour, depth string, extended int, basic int) string {
+	r, g, b := int(colour.Red()), int(colour.Green()), int(colour.Blue())
+	switch depth {
+	case Depth16:
+		index := nearestPaletteIndex(r, g, b, 16)
+		if index >= 8 {
+			return fmt.Sprintf("\x1b[%dm", basic+60+index-8)
+		}
+		return fmt.Sprintf("\x1b[%dm", basic+index)
+	case Depth256:
+		return fmt.Sprintf("\x1b[%d;5;%dm", extended, nearestPaletteIndex(r, g, b, 256))
+	default:
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", extended, r, g, b)
+	}
+}
+
+// nearestPaletteIndex returns the xterm palette entry among the first size
+// entries closest to r, g, b.
+func nearestPaletteIndex(r, g, b int, size int) int {
+	best, bestDistance := 0, -1
+	for i := 0; i < size; i++ {
+		pr, pg, pb := paletteColour(i)
+		distance := (pr-r)*(pr-r) + (pg-g)*(pg-g) + (pb-b)*(pb-b)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+var basePalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// paletteColour returns the RGB value of xterm palette entry index.
+func paletteColour(index int) (int, int, int) {
+	switch {
+	case index < 16:
+		c := basePalette[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return level(index / 36), level(index / 6 % 6), level(index % 6)
+	default:
+		v := 8 + (index-232)*10
+		return v, v, v
+	}
+}
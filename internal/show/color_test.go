@@ -0,0 +1,128 @@
+package show
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+func TestUseColor(t *testing.T) {
+	cases := []struct {
+		mode     string
+		terminal bool
+		noColor  string
+		term     string
+		want     bool
+	}{
+		{ColorAlways, false, "1", "xterm", true},
+		{ColorNever, true, "", "xterm", false},
+		{ColorAuto, true, "", "xterm", true},
+		{ColorAuto, false, "", "xterm", false},
+		{ColorAuto, true, "1", "xterm", false},
+		{ColorAuto, true, "", "dumb", false},
+		{"", false, "", "xterm", true},
+		{"", false, "1", "xterm", false},
+	}
+	for _, tc := range cases {
+		t.Setenv("NO_COLOR", tc.noColor)
+		t.Setenv("TERM", tc.term)
+		if got := useColor(tc.mode, tc.terminal); got != tc.want {
+			t.Fatalf("useColor(%q, %v) with NO_COLOR=%q TERM=%q: expected %v", tc.mode, tc.terminal, tc.noColor, tc.term, tc.want)
+		}
+	}
+}
+
+func TestColorDepth(t *testing.T) {
+	cases := []struct {
+		colorTerm string
+		term      string
+		want      string
+	}{
+		{"truecolor", "xterm", DepthTrueColor},
+		{"24bit", "screen", DepthTrueColor},
+		{"", "xterm-direct", DepthTrueColor},
+		{"", "tmux-256color", Depth256},
+		{"", "xterm", Depth16},
+		{"", "", Depth16},
+	}
+	for _, tc := range cases {
+		t.Setenv("COLORTERM", tc.colorTerm)
+		t.Setenv("TERM", tc.term)
+		if got := colorDepth(""); got != tc.want {
+			t.Fatalf("colorDepth with COLORTERM=%q TERM=%q: expected %q, got %q", tc.colorTerm, tc.term, tc.want, got)
+		}
+	}
+	if got := colorDepth(Depth256); got != Depth256 {
+		t.Fatalf("expected explicit depth to win, got %q", got)
+	}
+}
+
+func TestColourEscape(t *testing.T) {
+	red := chroma.MustParseColour("#ff0000")
+	cases := map[string]string{
+		DepthTrueColor: "\x1b[38;2;255;0;0m",
+		Depth256:       "\x1b[38;5;9m",
+		Depth16:        "\x1b[91m",
+	}
+	for depth, want := range cases {
+		if got := foregroundEscape(red, depth); got != want {
+			t.Fatalf("foregroundEscape at %s: expected %q, got %q", depth, want, got)
+		}
+	}
+	if got := backgroundEscape(chroma.MustParseColour("#000000"), Depth16); got != "\x1b[40m" {
+		t.Fatalf("expected black 16-colour background, got %q", got)
+	}
+	if got := nearestPaletteIndex(0x87, 0xaf, 0xd7, 256); got != 110 {
+		t.Fatalf("expected palette index 110, got %d", got)
+	}
+}
+
+func TestRunShowColorModes(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n")}}
+
+	t.Run("never", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Color: ColorNever})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if string(result.Content) != "1 | package main\n" {
+			t.Fatalf("expected plain output, got %q", result.Content)
+		}
+	})
+
+	t.Run("NO_COLOR disables highlighting", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go"})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if strings.Contains(string(result.Content), "\x1b[") {
+			t.Fatalf("expected no escapes with NO_COLOR, got %q", result.Content)
+		}
+	})
+
+	t.Run("always overrides NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Color: ColorAlways, ColorDepth: Depth256})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !strings.Contains(string(result.Content), "\x1b[38;5;") || strings.Contains(string(result.Content), "\x1b[38;2;") {
+			t.Fatalf("expected 256-colour escapes, got %q", result.Content)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Color: "sometimes"}); err == nil || !strings.Contains(err.Error(), "unknown color mode") {
+			t.Fatalf("expected unknown color mode error, got %v", err)
+		}
+		if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", ColorDepth: "8"}); err == nil || !strings.Contains(err.Error(), "unknown color depth") {
+			t.Fatalf("expected unknown color depth error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package show
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Debug output formats. DebugText writes "DEBUG file type" lines around the
+// content; DebugJSON writes one DebugRecord per file to Deps.Stderr.
+const (
+	DebugText = "text"
+	DebugJSON = "json"
+)
+
+func validateDebugFormat(format string) error {
+	switch format {
+	case "", DebugText, DebugJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown debug format: %s (want text or json)", format)
+	}
+}
+
+// DebugRecord describes how one file was rendered.
+type DebugRecord struct {
+	Path         string         `json:"path"`
+	ResolvedPath string         `json:"resolved_path,omitempty"`
+	Revision     string         `json:"revision,omitempty"`
+	Size         *int64         `json:"size,omitempty"`
+	Encoding     string         `json:"encoding"`
+	Lexer        DebugLexer     `json:"lexer"`
+	Detection    DebugDetection `json:"detection"`
+	Theme        string         `json:"theme"`
+	Formatter    string         `json:"formatter"`
+	Color        bool           `json:"color"`
+	ColorDepth   string         `json:"color_depth"`
+	// Lines is the number of lines in the file, or the number read when it
+	// could not be counted up front and rendering stopped early.
+	Lines int `json:"lines"`
+	// LineEndings is LineEndingsLF, LineEndingsCRLF, LineEndingsCR,
+	// LineEndingsMixed or LineEndingsNone, for the lines read.
+	LineEndings string `json:"line_endings"`
+	// TimingsMS holds milliseconds spent reading the start of the file,
+	// detecting its type, rendering it, and in total.
+	TimingsMS map[string]float64 `json:"timings_ms"`
+	// Error is why the file could not be shown, in which case the fields
+	// for the stages that were not reached are left empty.
+	Error string `json:"error,omitempty"`
+}
+
+type DebugLexer struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+type DebugDetection struct {
+	Rule       string  `json:"rule"`
+	Confidence float64 `json:"confidence"`
+}
+
+// debugRecorder fills in a DebugRecord as RunShowTo progresses. Its methods
+// do nothing on a nil recorder, so callers need not check whether JSON
+// debug output was requested.
+type debugRecorder struct {
+	record DebugRecord
+	start  time.Time
+	mark   time.Time
+	chunks *chunkReader
+}
+
+func newDebugRecorder(path string) *debugRecorder {
+	now := time.Now()
+	r := &debugRecorder{start: now, mark: now}
+	r.record.Path = path
+	r.record.TimingsMS = make(map[string]float64)
+	if path != StdinPath {
+		r.record.ResolvedPath = resolvePath(path)
+	}
+	return r
+}
+
+// revision records the git revision a "REV:path" spec names and resolves
+// its file path instead.
+func (r *debugRecorder) revision(ctx context.Context, rev, file string) {
+	if r == nil || rev == "" {
+		return
+	}
+	r.record.Revision = rev
+	r.record.ResolvedPath = revisionWorkTreePath(ctx, file)
+}
+
+// resolvePath returns path as an absolute path with symlinks resolved, as
+// far as the local file system allows.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// stage records the time since the previous stage ended.
+func (r *debugRecorder) stage(name string) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	r.record.TimingsMS[name] = milliseconds(now.Sub(r.mark))
+	r.mark = now
+}
+
+func (r *debugRecorder) source(size int64, sized bool, chunks *chunkReader, encoding string) {
+	if r == nil {
+		return
+	}
+	if sized {
+		r.record.Size = &size
+	}
+	r.chunks = chunks
+	r.record.Encoding = encoding
+}
+
+func (r *debugRecorder) lines(n int) {
+	if r == nil {
+		return
+	}
+	r.record.Lines = n
+}
+
+func (r *debugRecorder) detected(d detection) {
+	if r == nil {
+		return
+	}
+	r.record.Lexer.Name = d.name()
+	r.record.Lexer.Aliases = []string{}
+	if config := d.lexer.Config(); config != nil && config.Aliases != nil {
+		r.record.Lexer.Aliases = config.Aliases
+	}
+	r.record.Detection = DebugDetection{Rule: d.rule, Confidence: d.confidence}
+}
+
+func (r *debugRecorder) rendering(formatter string, theme string, color bool, depth string) {
+	if r == nil {
+		return
+	}
+	r.record.Formatter = formatter
+	r.record.Theme = theme
+	r.record.Color = color
+	r.record.ColorDepth = depth
+}
+
+// write finishes the record and writes it to w as one line of JSON. A
+// non-nil failure is recorded as the record's error.
+func (r *debugRecorder) write(w io.Writer, failure error) error {
+	if r == nil {
+		return nil
+	}
+	if failure != nil {
+		r.record.Error = failure.Error()
+	}
+	r.stage("render")
+	r.record.TimingsMS["total"] = milliseconds(time.Since(r.start))
+	if r.chunks != nil {
+		if r.record.Lines == 0 {
+			r.record.Lines = r.chunks.lines()
+		}
+		r.record.LineEndings = r.chunks.endings.convention()
+	}
+	data, err := json.Marshal(r.record)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
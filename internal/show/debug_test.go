@@ -0,0 +1,114 @@
+package show
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunShowDebugJSON(t *testing.T) {
+	setPlainEnv(t)
+
+	var stderr bytes.Buffer
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n\nfunc main() {}\n")}, Stderr: &stderr}
+	opts := ShowOptions{Path: "main.go", Debug: true, DebugFormat: DebugJSON, Theme: "monokai", Color: ColorAlways, ColorDepth: Depth256}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(string(result.Content), "DEBUG") {
+		t.Fatalf("expected no text debug lines in output, got %q", result.Content)
+	}
+
+	var record DebugRecord
+	if err := json.Unmarshal(stderr.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", stderr.String(), err)
+	}
+	if record.Path != "main.go" || record.ResolvedPath == "" {
+		t.Fatalf("unexpected paths: %+v", record)
+	}
+	if record.Size == nil || *record.Size != 29 {
+		t.Fatalf("expected size 29, got %v", record.Size)
+	}
+	if record.Encoding != "utf-8" || record.Lines != 3 {
+		t.Fatalf("unexpected encoding or lines: %+v", record)
+	}
+	if record.Lexer.Name != "Go" || len(record.Lexer.Aliases) == 0 {
+		t.Fatalf("unexpected lexer: %+v", record.Lexer)
+	}
+	if record.Detection.Rule != RulePath || record.Detection.Confidence <= 0 {
+		t.Fatalf("unexpected detection: %+v", record.Detection)
+	}
+	if record.Theme != "monokai" || record.Formatter != "terminal256" || !record.Color || record.ColorDepth != Depth256 {
+		t.Fatalf("unexpected rendering details: %+v", record)
+	}
+	for _, stage := range []string{"read", "detect", "render", "total"} {
+		if _, ok := record.TimingsMS[stage]; !ok {
+			t.Fatalf("expected %s timing, got %v", stage, record.TimingsMS)
+		}
+	}
+}
+
+func TestRunShowDebugJSONStreamed(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 16)
+
+	var stderr bytes.Buffer
+	deps := Deps{FileReader: streamReader{data: strings.Repeat("line\n", 10) + "tail"}, Stderr: &stderr}
+	opts := ShowOptions{Path: StdinPath, Debug: true, DebugFormat: DebugJSON, Decorations: DecorationsNever}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(string(result.Content), "line\n") {
+		t.Fatalf("expected plain content, got %q", result.Content)
+	}
+
+	var record DebugRecord
+	if err := json.Unmarshal(stderr.Bytes(), &record); err != nil {
+		t.Fatalf("expected JSON record, got %q: %v", stderr.String(), err)
+	}
+	if record.Lines != 11 || record.Size != nil || record.ResolvedPath != "" || record.Formatter != "passthrough" {
+		t.Fatalf("unexpected record for streamed stdin: %+v", record)
+	}
+}
+
+func TestRunShowDebugJSONFailure(t *testing.T) {
+	setPlainEnv(t)
+
+	for name, tt := range map[string]struct {
+		reader FileReader
+		want   string
+	}{
+		"not found": {stubReader{err: os.ErrNotExist}, "file does not exist"},
+		"binary":    {stubReader{data: []byte("\x00\x01\x02")}, ErrBinary.Error()},
+	} {
+		var stderr bytes.Buffer
+		deps := Deps{FileReader: tt.reader, Stderr: &stderr}
+		opts := ShowOptions{Path: "a.bin", Debug: true, DebugFormat: DebugJSON}
+		_, err := RunShow(t.Context(), deps, opts)
+		if err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+
+		var record DebugRecord
+		if err := json.Unmarshal(stderr.Bytes(), &record); err != nil {
+			t.Fatalf("%s: expected one JSON record, got %q: %v", name, stderr.String(), err)
+		}
+		if record.Path != "a.bin" || record.Error != err.Error() || !strings.Contains(record.Error, tt.want) {
+			t.Fatalf("%s: expected the error in the record, got %+v", name, record)
+		}
+	}
+}
+
+func TestRunShowDebugFormatErrors(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("x\n")}}
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Debug: true, DebugFormat: "yaml"}); err == nil || !strings.Contains(err.Error(), "unknown debug format") {
+		t.Fatalf("expected unknown debug format error, got %v", err)
+	}
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Debug: true, DebugFormat: DebugJSON}); err == nil || !strings.Contains(err.Error(), "stderr is required") {
+		t.Fatalf("expected missing stderr error, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package show
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// Detection rules, from most to least authoritative. detectLexer tries them
+// in this order and stops at the first that names a lexer.
+const (
+	RuleForced       = "forced"
+	RuleMapping      = "mapping"
+	RuleModeline     = "modeline"
+	RulePath         = "path"
+	RuleShebang      = "shebang"
+	RuleEditorConfig = "editorconfig"
+	RuleAnalysis     = "analysis"
+	RuleFallback     = "fallback"
+)
+
+// analysisConfidence caps the confidence of content analysis, which only
+// guesses from patterns in the text.
+const analysisConfidence = 0.7
+
+// detection is the lexer chosen for a file and the rule that chose it.
+type detection struct {
+	lexer      chroma.Lexer
+	rule       string
+	confidence float64
+}
+
+// name is the lexer's display name, or "unknown" when nothing better than
+// the fallback lexer matched.
+func (d detection) name() string {
+	if d.rule == RuleFallback || d.lexer == nil {
+		return "unknown"
+	}
+	if config := d.lexer.Config(); config != nil && config.Name != "" {
+		return config.Name
+	}
+	return "unknown"
+}
+
+// detectLexer picks the lexer for a file. sample is the start of the file
+// and tail its end, or nil when the end is not known. reader, when not nil,
+// is used to look for .editorconfig files next to path and in its parent
+// directories.
+func detectLexer(ctx context.Context, reader FileReader, path string, sample, tail []byte, fileType string, mappings []SyntaxMapping) (detection, error) {
+	if fileType != "" {
+		lexer := lexers.Get(fileType)
+		if lexer == nil {
+			return detection{}, fmt.Errorf("unknown file type: %s", fileType)
+		}
+		return newDetection(lexer, RuleForced, 1), nil
+	}
+
+	if name := mappedFileType(path, mappings); name != "" {
+		if lexer := lexers.Get(name); lexer != nil {
+			return newDetection(lexer, RuleMapping, 1), nil
+		}
+	}
+	if lexer := lookupLexer(modelineFileType(sample, tail)); lexer != nil {
+		return newDetection(lexer, RuleModeline, 0.95), nil
+	}
+	if path != StdinPath {
+		if lexer := lexers.Match(path); lexer != nil {
+			return newDetection(lexer, RulePath, 0.9), nil
+		}
+	}
+	if lexer := lookupLexer(shebangInterpreter(sample)); lexer != nil {
+		return newDetection(lexer, RuleShebang, 0.85), nil
+	}
+	if reader != nil && path != StdinPath {
+		if lexer := lookupLexer(editorConfigFileType(ctx, reader, path)); lexer != nil {
+			return newDetection(lexer, RuleEditorConfig, 0.8), nil
+		}
+	}
+	if lexer, weight := analyse(string(sample)); lexer != nil {
+		return newDetection(lexer, RuleAnalysis, min(float64(weight), 1)*analysisConfidence), nil
+	}
+	return newDetection(lexers.Fallback, RuleFallback, 0), nil
+}
+
+func newDetection(lexer chroma.Lexer, rule string, confidence float64) detection {
+	return detection{lexer: chromaCoalesce(lexer), rule: rule, confidence: confidence}
+}
+
+// analyse is lexers.Analyse, but also returns the winning weight.
+func analyse(text string) (chroma.Lexer, float32) {
+	var picked chroma.Lexer
+	var highest float32
+	for _, lexer := range lexers.GlobalLexerRegistry.Lexers {
+		analyser, ok := lexer.(chroma.Analyser)
+		if !ok {
+			continue
+		}
+		if weight := analyser.AnalyseText(text); weight > highest {
+			picked, highest = lexer, weight
+		}
+	}
+	return picked, highest
+}
+
+// interpreterFileTypes maps interpreter and editor mode names that are not
+// Chroma aliases to one that is.
+var interpreterFileTypes = map[string]string{
+	"node":         "javascript",
+	"nodejs":       "javascript",
+	"deno":         "typescript",
+	"pwsh":         "powershell",
+	"shell-script": "bash",
+	"dash":         "bash",
+	"ash":          "bash",
+	"rscript":      "r",
+	"runghc":       "haskell",
+	"runhaskell":   "haskell",
+}
+
+// lookupLexer finds the lexer for an interpreter or mode name, retrying
+// without a version suffix so python3.12 finds Python.
+func lookupLexer(name string) chroma.Lexer {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	for _, candidate := range []string{name, strings.TrimRight(name, "0123456789.")} {
+		if candidate == "" {
+			continue
+		}
+		if alias, ok := interpreterFileTypes[candidate]; ok {
+			candidate = alias
+		}
+		if lexer := lexers.Get(candidate); lexer != nil {
+			return lexer
+		}
+	}
+	return nil
+}
+
+// shebangInterpreter returns the interpreter named by a #! first line,
+// looking through env and its options.
+func shebangInterpreter(sample []byte) string {
+	line, _, _ := strings.Cut(string(sample[:min(len(sample), 256)]), "\n")
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+	for _, arg := range fields[1:] {
+		if strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+			continue
+		}
+		return filepath.Base(arg)
+	}
+	return ""
+}
+
+var (
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vi|vim|ex)(?:[<=>]?\d+)?:(.*)`)
+	vimFileType   = regexp.MustCompile(`(?:^|[\s:])(?:ft|filetype|syn|syntax)=([\w+.-]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-(.*?)-\*-`)
+	emacsMode     = regexp.MustCompile(`(?i)(?:^|;)\s*mode:\s*([\w+.-]+)`)
+)
+
+// modelineLines is how many lines at each end of a file are searched for
+// modelines, matching Vim's default 'modelines' setting.
+const modelineLines = 5
+
+// modelineTailSize is how much of the end of a file is kept for its last
+// modeline lines.
+const modelineTailSize = 4096
+
+// modelineFileType returns the file type set by a Vim modeline in the first
+// lines of head or the last lines of tail, or by an Emacs -*- line on the
+// first line of head, or the second after a #! line. head is the start of
+// the file and tail its end, or nil when the end is not known.
+func modelineFileType(head, tail []byte) string {
+	lines := modelineSplit(head)
+	for i, line := range lines {
+		if i >= 2 || (i == 1 && !strings.HasPrefix(lines[0], "#!")) {
+			break
+		}
+		if m := emacsModeline.FindStringSubmatch(line); m != nil {
+			vars := strings.TrimSpace(m[1])
+			if !strings.Contains(vars, ":") {
+				return strings.TrimSuffix(vars, "-mode")
+			}
+			if mode := emacsMode.FindStringSubmatch(vars); mode != nil {
+				return strings.TrimSuffix(mode[1], "-mode")
+			}
+		}
+	}
+
+	candidates := lines[:min(len(lines), modelineLines):min(len(lines), modelineLines)]
+	last := modelineSplit(tail)
+	candidates = append(candidates, last[max(len(last)-modelineLines, 0):]...)
+	for _, line := range candidates {
+		if m := vimModeline.FindStringSubmatch(line); m != nil {
+			if ft := vimFileType.FindStringSubmatch(m[1]); ft != nil {
+				return ft[1]
+			}
+		}
+	}
+	return ""
+}
+
+func modelineSplit(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+}
@@ -0,0 +1,150 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLexerRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		sample   string
+		fileType string
+		mappings []SyntaxMapping
+		rule     string
+		want     string
+	}{
+		{"forced", "main.go", "package main\n", "python", nil, RuleForced, "Python"},
+		{"mapping", "page.tpl", "x\n", "", []SyntaxMapping{{Pattern: "*.tpl", FileType: "go"}}, RuleMapping, "Go"},
+		{"modeline before path", "notes.txt", "x\n# vim: set ft=python :\n", "", nil, RuleModeline, "Python"},
+		{"path", "main.go", "#!/bin/sh\n", "", nil, RulePath, "Go"},
+		{"shebang", "deploy", "#!/usr/bin/env python3\nprint(1)\n", "", nil, RuleShebang, "Python"},
+		{"stdin shebang", StdinPath, "#!/bin/bash\necho hi\n", "", nil, RuleShebang, "Bash"},
+		{"analysis", StdinPath, "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n", "", nil, RuleAnalysis, "Go"},
+		{"fallback", "notes", "just words\n", "", nil, RuleFallback, "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := detectLexer(t.Context(), nil, tt.path, []byte(tt.sample), []byte(tt.sample), tt.fileType, tt.mappings)
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			if d.rule != tt.rule || d.name() != tt.want {
+				t.Fatalf("expected %s via %s, got %s via %s", tt.want, tt.rule, d.name(), d.rule)
+			}
+			if d.confidence < 0 || d.confidence > 1 {
+				t.Fatalf("confidence out of range: %v", d.confidence)
+			}
+		})
+	}
+}
+
+func TestDetectLexerUnknownFileType(t *testing.T) {
+	if _, err := detectLexer(t.Context(), nil, "main.go", nil, nil, "no-such-type", nil); err == nil || !strings.Contains(err.Error(), "unknown file type") {
+		t.Fatalf("expected unknown file type error, got %v", err)
+	}
+}
+
+func TestShebangInterpreter(t *testing.T) {
+	tests := map[string]string{
+		"#!/bin/bash\n":                        "bash",
+		"#!/usr/bin/env python3\n":             "python3",
+		"#!/usr/bin/env -S deno run --allow\n": "deno",
+		"#!/usr/bin/env FOO=1 node\n":          "node",
+		"#! /usr/bin/perl -w\n":                "perl",
+		"echo hi\n":                            "",
+		"#!\n":                                 "",
+	}
+	for sample, want := range tests {
+		if got := shebangInterpreter([]byte(sample)); got != want {
+			t.Fatalf("shebangInterpreter(%q) = %q, want %q", sample, got, want)
+		}
+	}
+}
+
+func TestLookupLexer(t *testing.T) {
+	tests := map[string]string{
+		"python3.12": "Python",
+		"node":       "JavaScript",
+		"sh":         "Bash",
+		"Ruby":       "Ruby",
+	}
+	for name, want := range tests {
+		lexer := lookupLexer(name)
+		if lexer == nil || lexer.Config().Name != want {
+			t.Fatalf("lookupLexer(%q) = %v, want %s", name, lexer, want)
+		}
+	}
+	if lookupLexer("") != nil {
+		t.Fatal("expected no lexer for an empty name")
+	}
+}
+
+func TestModelineFileType(t *testing.T) {
+	tests := map[string]string{
+		"# vim: set ft=ruby :\n":                         "ruby",
+		"x\n/* vi:syntax=c */\n":                         "c",
+		"// vim: ts=4 filetype=javascript\n":             "javascript",
+		"# -*- python -*-\n":                             "python",
+		"#!/bin/sh\n# -*- mode: sh; coding: utf-8 -*-\n": "sh",
+		";; -*- Mode: emacs-lisp -*-\n":                  "emacs-lisp",
+		"x\n# -*- python -*-\n":                          "",
+		"plain text: ft=go\n":                            "",
+		strings.Repeat("x\n", 6) + "# vim: ft=go\n" + strings.Repeat("x\n", 6): "",
+		strings.Repeat("x\n", 20) + "# vim: ft=go\n":                           "go",
+	}
+	for sample, want := range tests {
+		if got := modelineFileType([]byte(sample), []byte(sample)); got != want {
+			t.Fatalf("modelineFileType(%q) = %q, want %q", sample, got, want)
+		}
+	}
+
+	head := strings.Repeat("x\n", 20) + "# vim: ft=go\n"
+	if got := modelineFileType([]byte(head), nil); got != "" {
+		t.Fatalf("expected no modeline without the end of the file, got %q", got)
+	}
+	if got := modelineFileType([]byte("x\n"), []byte("partial\ny\n# vim: ft=go\n")); got != "go" {
+		t.Fatalf("expected the modeline at the end of the file, got %q", got)
+	}
+}
+
+func TestRunShowModelineAtEnd(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 64)
+
+	body := strings.Repeat("some words\n", 20)
+	for name, tt := range map[string]struct {
+		reader FileReader
+		want   string
+	}{
+		"seekable":           {stubReader{data: []byte(body + "# vim: ft=python\n")}, "Python"},
+		"end of first chunk": {stubReader{data: []byte(strings.Repeat("x\n", 28) + "# vim: ft=python\n" + body)}, "unknown"},
+		"piped past a chunk": {streamReader{data: body + "# vim: ft=python\n"}, "unknown"},
+		"piped in one chunk": {streamReader{data: "x\n# vim: ft=python\n"}, "Python"},
+	} {
+		result, err := RunShow(t.Context(), Deps{FileReader: tt.reader}, ShowOptions{Path: "notes", Debug: true})
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", name, err)
+		}
+		if !strings.Contains(string(result.Content), "DEBUG file type: "+tt.want+" (") {
+			t.Fatalf("%s: expected %s, got %q", name, tt.want, result.Content)
+		}
+	}
+}
+
+func TestRunShowDebugDetection(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	result, err := RunShow(t.Context(), Deps{FileReader: pathReader{"deploy": "#!/usr/bin/env python3\nprint(1)\n"}}, ShowOptions{Path: "deploy", Debug: true, Header: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	output := string(result.Content)
+	if strings.Count(output, "DEBUG file type: Python (shebang, confidence 0.85)") != 2 {
+		t.Fatalf("expected shebang detection in debug output, got %q", output)
+	}
+	if !strings.Contains(output, "File: deploy (Python,") {
+		t.Fatalf("expected header to agree with debug output, got %q", output)
+	}
+}
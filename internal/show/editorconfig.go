@@ -0,0 +1,154 @@
+package show
+
+import (
+	"bufio"
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// editorConfigProperty is the .editorconfig property that sets the file
+// type, e.g.
+//
+//	[Jenkinsfile*]
+//	show_filetype = groovy
+const editorConfigProperty = "show_filetype"
+
+// editorConfigMaxSize skips .editorconfig files too large to be real ones.
+const editorConfigMaxSize = 64 * 1024
+
+// editorConfigFileType looks for editorConfigProperty in the .editorconfig
+// files that apply to path, nearest first, stopping at one that sets
+// root = true. Unreadable files are skipped.
+func editorConfigFileType(ctx context.Context, reader FileReader, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		data, err := readFileContext(ctx, reader, filepath.Join(dir, ".editorconfig"))
+		if err == nil && len(data) <= editorConfigMaxSize {
+			rel, relErr := filepath.Rel(dir, abs)
+			if relErr == nil {
+				value, root := editorConfigValue(string(data), filepath.ToSlash(rel), editorConfigProperty)
+				if value != "" {
+					return value
+				}
+				if root {
+					return ""
+				}
+			}
+		}
+		if ctx.Err() != nil || filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// editorConfigValue returns the value the last section matching rel sets
+// for key, and whether the file declares root = true.
+func editorConfigValue(data string, rel string, key string) (value string, root bool) {
+	matches := false
+	preamble := true
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			preamble = false
+			matches = editorConfigGlob(line[1 : len(line)-1]).MatchString(rel)
+			continue
+		}
+		name, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		val = strings.TrimSpace(val)
+		switch {
+		case preamble && name == "root":
+			root = strings.EqualFold(val, "true")
+		case matches && name == key:
+			value = val
+		}
+	}
+	return value, root
+}
+
+// editorConfigGlob compiles an EditorConfig section glob. Globs without a
+// slash match in any directory; * and ? stop at slashes, ** does not, and
+// {a,b} matches either alternative.
+func editorConfigGlob(glob string) *regexp.Regexp {
+	if strings.HasPrefix(glob, "/") {
+		glob = glob[1:]
+	} else if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end
+		case '{':
+			depth++
+			b.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				b.WriteString(`\}`)
+				continue
+			}
+			depth--
+			b.WriteString(")")
+		case ',':
+			if depth == 0 {
+				b.WriteString(",")
+				continue
+			}
+			b.WriteString("|")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	for ; depth > 0; depth-- {
+		b.WriteString(")")
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile(`[^\s\S]`)
+	}
+	return re
+}
@@ -0,0 +1,85 @@
+package show
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorConfigGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		rel  string
+		want bool
+	}{
+		{"Jenkinsfile*", "Jenkinsfile", true},
+		{"Jenkinsfile*", "ci/Jenkinsfile.release", true},
+		{"*.{tpl,tmpl}", "web/page.tmpl", true},
+		{"*.{tpl,tmpl}", "web/page.html", false},
+		{"bin/*", "bin/deploy", true},
+		{"bin/*", "tools/bin/deploy", false},
+		{"/bin/**", "bin/a/b", true},
+		{"**/scripts/*", "a/b/scripts/run", true},
+		{"run[0-9]", "run7", true},
+		{"run[!0-9]", "run7", false},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+	}
+	for _, tt := range tests {
+		if got := editorConfigGlob(tt.glob).MatchString(tt.rel); got != tt.want {
+			t.Fatalf("glob %q on %q = %v, want %v", tt.glob, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestEditorConfigValue(t *testing.T) {
+	data := `root = true
+
+[*]
+indent_style = space
+
+[deploy*]
+show_filetype = bash
+
+; later sections win
+[deploy-prod]
+SHOW_FILETYPE = python
+`
+	value, root := editorConfigValue(data, "scripts/deploy-prod", editorConfigProperty)
+	if value != "python" || !root {
+		t.Fatalf("expected python from last matching section and root, got %q, %v", value, root)
+	}
+	if value, _ := editorConfigValue(data, "deploy-dev", editorConfigProperty); value != "bash" {
+		t.Fatalf("expected bash, got %q", value)
+	}
+	if value, _ := editorConfigValue(data, "README", editorConfigProperty); value != "" {
+		t.Fatalf("expected no value, got %q", value)
+	}
+}
+
+func TestDetectLexerEditorConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte("root = true\n[scripts/*]\nshow_filetype = bash\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "scripts", "deploy")
+
+	d, err := detectLexer(t.Context(), OSFileReader{}, path, []byte("set -e\n"), nil, "", nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if d.rule != RuleEditorConfig || d.name() != "Bash" {
+		t.Fatalf("expected Bash via editorconfig, got %s via %s", d.name(), d.rule)
+	}
+
+	d, err = detectLexer(t.Context(), OSFileReader{}, path, []byte("#!/usr/bin/env python3\n"), nil, "", nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if d.rule != RuleShebang {
+		t.Fatalf("expected shebang to win over editorconfig, got %s", d.rule)
+	}
+}
@@ -0,0 +1,251 @@
+package show
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// Encodings detected from the input or set with ShowOptions.Encoding.
+// Everything but UTF-8 is transcoded to UTF-8 before highlighting.
+// EncodingUTF8BOM and EncodingBinary are only ever detected: the first is
+// shown without its byte order mark unless ShowOptions.ShowAll draws it, and
+// the second is refused or shown as a hexdump.
+const (
+	EncodingAuto        = "auto"
+	EncodingUTF8        = "utf-8"
+	EncodingUTF8BOM     = "utf-8-bom"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingUTF32LE     = "utf-32le"
+	EncodingUTF32BE     = "utf-32be"
+	EncodingLatin1      = "iso-8859-1"
+	EncodingWindows1252 = "windows-1252"
+	EncodingShiftJIS    = "shift_jis"
+	EncodingBinary      = "binary"
+)
+
+// byteOrderMark is U+FEFF as it appears at the start of decoded text.
+const byteOrderMark = "\ufeff"
+
+// encodingSampleSize is how much of the input detectEncoding looks at.
+const encodingSampleSize = binarySampleSize
+
+// encodingNames maps accepted spellings, lowercased and without dashes or
+// underscores, to an encoding name.
+var encodingNames = map[string]string{
+	"utf8":        EncodingUTF8,
+	"utf16le":     EncodingUTF16LE,
+	"utf16be":     EncodingUTF16BE,
+	"utf32le":     EncodingUTF32LE,
+	"utf32be":     EncodingUTF32BE,
+	"iso88591":    EncodingLatin1,
+	"latin1":      EncodingLatin1,
+	"windows1252": EncodingWindows1252,
+	"cp1252":      EncodingWindows1252,
+	"shiftjis":    EncodingShiftJIS,
+	"sjis":        EncodingShiftJIS,
+}
+
+// Encodings lists the names ShowOptions.Encoding accepts besides
+// EncodingAuto, in the order they are documented.
+func Encodings() []string {
+	return []string{
+		EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE, EncodingUTF32LE, EncodingUTF32BE,
+		EncodingLatin1, EncodingWindows1252, EncodingShiftJIS,
+	}
+}
+
+// ParseEncoding returns the canonical name for an encoding, accepting
+// common aliases such as latin1, cp1252 and sjis.
+func ParseEncoding(name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" || key == EncodingAuto {
+		return "", nil
+	}
+	key = strings.NewReplacer("-", "", "_", "").Replace(key)
+	if canonical, ok := encodingNames[key]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("unknown encoding: %s (want auto, %s)", name, strings.Join(Encodings(), ", "))
+}
+
+// decoder returns the encoding that transcodes name to UTF-8, or nil when
+// the input is used as is. A leading byte order mark is dropped unless
+// keepBOM is set, in which case it is decoded to U+FEFF like any other
+// character, for ShowOptions.ShowAll to draw.
+func decoder(name string, keepBOM bool) encoding.Encoding {
+	bom, bom32 := unicode.UseBOM, utf32.UseBOM
+	if keepBOM {
+		bom, bom32 = unicode.IgnoreBOM, utf32.IgnoreBOM
+	}
+	switch name {
+	case EncodingUTF8BOM:
+		if keepBOM {
+			return nil
+		}
+		return unicode.UTF8BOM
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, bom)
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, bom)
+	case EncodingUTF32LE:
+		return utf32.UTF32(utf32.LittleEndian, bom32)
+	case EncodingUTF32BE:
+		return utf32.UTF32(utf32.BigEndian, bom32)
+	case EncodingLatin1:
+		return charmap.ISO8859_1
+	case EncodingWindows1252:
+		return charmap.Windows1252
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS
+	default:
+		return nil
+	}
+}
+
+// detectEncoding names the encoding of sample, the start of the input. A
+// byte order mark decides it; otherwise the placement of zero bytes points
+// to UTF-16 or UTF-32, and input that is not valid UTF-8 is checked for runs
+// of Shift-JIS double-byte characters before falling back to an 8-bit
+// encoding. Windows-1252 is preferred over ISO-8859-1 when bytes 0x80-0x9f,
+// which are control codes in the latter, occur.
+func detectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xef, 0xbb, 0xbf}):
+		return EncodingUTF8BOM
+	case bytes.HasPrefix(sample, []byte{0xff, 0xfe, 0, 0}):
+		return EncodingUTF32LE
+	case bytes.HasPrefix(sample, []byte{0, 0, 0xfe, 0xff}):
+		return EncodingUTF32BE
+	case bytes.HasPrefix(sample, []byte{0xff, 0xfe}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(sample, []byte{0xfe, 0xff}):
+		return EncodingUTF16BE
+	}
+	if name := wideEncoding(sample); name != "" {
+		return name
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return EncodingBinary
+	}
+	text := sample
+	if len(text) >= encodingSampleSize {
+		// The sample may have cut the last character short.
+		text = text[:incompleteRuneStart(text)]
+	}
+	if utf8.Valid(text) {
+		return EncodingUTF8
+	}
+	if isShiftJIS(sample) {
+		return EncodingShiftJIS
+	}
+	if isBinary(sample) {
+		return EncodingBinary
+	}
+	for _, c := range sample {
+		if c >= 0x80 && c <= 0x9f {
+			return EncodingWindows1252
+		}
+	}
+	return EncodingLatin1
+}
+
+// wideEncoding recognises UTF-16 and UTF-32 without a byte order mark from
+// text that is mostly below U+0100, where every code unit has zero high
+// bytes and a non-zero low byte.
+func wideEncoding(sample []byte) string {
+	if len(sample) >= 8 {
+		units := len(sample) / 4
+		var zeros [4]int
+		for i := 0; i < units*4; i++ {
+			if sample[i] == 0 {
+				zeros[i%4]++
+			}
+		}
+		switch {
+		case zeros[2] == units && zeros[3] == units && zeros[0] <= units/100:
+			return EncodingUTF32LE
+		case zeros[0] == units && zeros[1] == units && zeros[3] <= units/100:
+			return EncodingUTF32BE
+		}
+	}
+	if len(sample) >= 4 {
+		units := len(sample) / 2
+		var zeros [2]int
+		for i := 0; i < units*2; i++ {
+			if sample[i] == 0 {
+				zeros[i%2]++
+			}
+		}
+		switch {
+		case zeros[1]*10 >= units*4 && zeros[0] <= units/100:
+			return EncodingUTF16LE
+		case zeros[0]*10 >= units*4 && zeros[1] <= units/100:
+			return EncodingUTF16BE
+		}
+	}
+	return ""
+}
+
+// isShiftJIS reports whether sample is valid Shift-JIS in which most
+// double-byte characters stand next to other non-ASCII characters, as they
+// do in Japanese text. An accented letter followed by an ASCII one can form
+// a valid pair too, such as "\xfcn" in Latin-1 "M\xfcnchen", but it stands
+// alone between ASCII letters. A lead byte cut off at the end is allowed.
+func isShiftJIS(sample []byte) bool {
+	pairs, joined := 0, 0
+	wide := false
+	for i := 0; i < len(sample); i++ {
+		c := sample[i]
+		switch {
+		case c < 0x80:
+			wide = false
+		case c >= 0xa1 && c <= 0xdf:
+			wide = true
+		case (c >= 0x81 && c <= 0x9f) || (c >= 0xe0 && c <= 0xfc):
+			if i+1 == len(sample) {
+				break
+			}
+			trail := sample[i+1]
+			if trail < 0x40 || trail == 0x7f || trail > 0xfc {
+				return false
+			}
+			pairs++
+			if wide || (i+2 < len(sample) && sample[i+2] >= 0x80) {
+				joined++
+			}
+			wide = true
+			i++
+		default:
+			return false
+		}
+	}
+	return pairs > 0 && joined*2 >= pairs
+}
+
+// peekSource returns up to n bytes from the start of src and a reader that
+// still yields all of src: src itself, rewound, when it can seek.
+func peekSource(src io.Reader, n int) ([]byte, io.Reader, error) {
+	sample := make([]byte, n)
+	read, err := io.ReadFull(src, sample)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+	sample = sample[:read]
+	if seeker, ok := src.(io.ReadSeeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+			return sample, seeker, nil
+		}
+	}
+	return sample, io.MultiReader(bytes.NewReader(sample), src), nil
+}
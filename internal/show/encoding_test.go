@@ -0,0 +1,137 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                                EncodingUTF8,
+		"plain":                           EncodingUTF8,
+		"h\xc3\xa9":                       EncodingUTF8,
+		"\xef\xbb\xbfbom":                 EncodingUTF8BOM,
+		"\xff\xfeh\x00":                   EncodingUTF16LE,
+		"\xfe\xff\x00h":                   EncodingUTF16BE,
+		"\xff\xfe\x00\x00h\x00\x00\x00":   EncodingUTF32LE,
+		"\x00\x00\xfe\xff\x00\x00\x00h":   EncodingUTF32BE,
+		"h\x00i\x00!\x00\n\x00":           EncodingUTF16LE,
+		"\x00h\x00i\x00!\x00\n":           EncodingUTF16BE,
+		"h\x00\x00\x00i\x00\x00\x00":      EncodingUTF32LE,
+		"\x00\x00\x00h\x00\x00\x00i":      EncodingUTF32BE,
+		"a\x00b":                          EncodingBinary,
+		"\x7fELF\x02\x01\x01\x00\x00\x00": EncodingBinary,
+		"caf\xe9":                         EncodingLatin1,
+		"\x93quoted\x94 caf\xe9":          EncodingWindows1252,
+		"\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd":               EncodingShiftJIS,
+		"// \x93\xfa\x96{\x8c\xea\x82\xcc\x83e\x83L\x83X\x83g\n": EncodingShiftJIS,
+		"M\xfcnchen":                                EncodingLatin1,
+		"K\xf6nigstra\xdfe 5, M\xfcnchen":           EncodingLatin1,
+		"caf\xe9s r\xe9serv\xe9s":                   EncodingLatin1,
+		"Cr\xe8me br\xfbl\xe9e, na\xefve fa\xe7ade": EncodingLatin1,
+		"\x93Gr\xfc\xdfe aus M\xfcnchen\x94":        EncodingWindows1252,
+	}
+	for sample, want := range tests {
+		if got := detectEncoding([]byte(sample)); got != want {
+			t.Fatalf("detectEncoding(%q) = %q, want %q", sample, got, want)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":          "",
+		"auto":      "",
+		"UTF-8":     EncodingUTF8,
+		"utf16le":   EncodingUTF16LE,
+		"latin1":    EncodingLatin1,
+		"CP1252":    EncodingWindows1252,
+		"Shift_JIS": EncodingShiftJIS,
+		"sjis":      EncodingShiftJIS,
+	}
+	for name, want := range tests {
+		got, err := ParseEncoding(name)
+		if err != nil || got != want {
+			t.Fatalf("ParseEncoding(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("ebcdic"); err == nil || !strings.Contains(err.Error(), "unknown encoding: ebcdic") {
+		t.Fatalf("expected unknown encoding error, got %v", err)
+	}
+}
+
+func TestRunShowTranscodes(t *testing.T) {
+	setPlainEnv(t)
+	tests := []struct {
+		name     string
+		data     string
+		encoding string
+	}{
+		{"utf-16le bom", "\xff\xfek\x00:\x00 \x00\xe9\x00\n\x00", ""},
+		{"utf-16be", "\x00k\x00:\x00 \x00\xe9\x00\n", ""},
+		{"utf-16be bom", "\xfe\xff\x00k\x00:\x00 \x00\xe9\x00\n", ""},
+		{"utf-32le bom", "\xff\xfe\x00\x00k\x00\x00\x00:\x00\x00\x00 \x00\x00\x00\xe9\x00\x00\x00\n\x00\x00\x00", ""},
+		{"utf-8 bom", "\xef\xbb\xbfk: \xc3\xa9\n", ""},
+		{"forced utf-16le bom", "\xff\xfek\x00:\x00 \x00\xe9\x00\n\x00", "utf-16le"},
+		{"latin-1", "k: \xe9\n", ""},
+		{"forced", "k: \xe9\n", "windows-1252"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := Deps{FileReader: stubReader{data: []byte(tt.data)}}
+			result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.yaml", Encoding: tt.encoding})
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			got := strings.TrimPrefix(stripEscapes(string(result.Content)), "1 | ")
+			if got != "k: é\n" {
+				t.Fatalf("expected transcoded content, got %q", got)
+			}
+		})
+	}
+
+	deps := Deps{FileReader: stubReader{data: []byte("ok\n")}}
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Encoding: "ebcdic"}); err == nil {
+		t.Fatal("expected unknown encoding error")
+	}
+}
+
+func TestRunShowDetectsAfterBOM(t *testing.T) {
+	setPlainEnv(t)
+	for name, data := range map[string]string{
+		"utf-8":    "\xef\xbb\xbf#!/usr/bin/env python3\nprint(1)\n",
+		"utf-16le": "\xff\xfe#\x00!\x00/\x00b\x00i\x00n\x00/\x00s\x00h\x00\n\x00",
+	} {
+		deps := Deps{FileReader: stubReader{data: []byte(data)}}
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "deploy", Debug: true, Decorations: DecorationsNever})
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", name, err)
+		}
+		got := string(result.Content)
+		if !strings.Contains(got, "(shebang,") || strings.Contains(got, "\ufeff") {
+			t.Fatalf("%s: expected shebang detection without the BOM, got %q", name, got)
+		}
+	}
+}
+
+func TestRunShowAllKeepsBOM(t *testing.T) {
+	setPlainEnv(t)
+	for name, data := range map[string]string{
+		"utf-8":    "\xef\xbb\xbf#!/bin/sh\n",
+		"utf-16le": "\xff\xfe#\x00!\x00/\x00b\x00i\x00n\x00/\x00s\x00h\x00\n\x00",
+		"utf-16be": "\xfe\xff\x00#\x00!\x00/\x00b\x00i\x00n\x00/\x00s\x00h\x00\n",
+	} {
+		deps := Deps{FileReader: stubReader{data: []byte(data)}}
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "deploy", ShowAll: true, Debug: true})
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", name, err)
+		}
+		got := stripEscapes(string(result.Content))
+		if !strings.Contains(got, "1 | <U+FEFF>#!/bin/sh$\n") {
+			t.Fatalf("%s: expected the byte order mark drawn on line 1, got %q", name, got)
+		}
+		if !strings.Contains(got, "(shebang,") {
+			t.Fatalf("%s: expected shebang detection past the byte order mark, got %q", name, got)
+		}
+	}
+}
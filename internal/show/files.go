@@ -0,0 +1,122 @@
+package show
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// FileResult is the rendered output, or the error, for one path passed to
+// RunShowFiles or RunShowFilesTo.
+type FileResult struct {
+	Path string
+	// Content is the rendered output. RunShowFilesTo leaves it empty, as
+	// the output has already been written.
+	Content []byte
+	Err     error
+}
+
+// RunShowFiles renders each path in order with its own lexer and line
+// numbering. A failure on one path is recorded in its FileResult and does
+// not stop the remaining paths.
+func RunShowFiles(ctx context.Context, deps Deps, opts ShowOptions, paths []string) []FileResult {
+	results := make([]FileResult, 0, len(paths))
+	for _, path := range paths {
+		var b bytes.Buffer
+		fileOpts := opts
+		fileOpts.Path = path
+		err := RunShowTo(ctx, deps, fileOpts, &b)
+		results = append(results, FileResult{Path: path, Content: b.Bytes(), Err: err})
+	}
+	return results
+}
+
+// RunShowFilesTo streams each path to w in order, like RunShowFiles, with a
+// blank line between files when headers are shown. The blank line is only
+// written once the next file has output, so a file that fails leaves no gap
+// behind. A failure on one path is recorded in its FileResult and does not
+// stop the remaining paths, but a failed write to w does: the last result
+// then holds the write error and the paths after it are left out.
+func RunShowFilesTo(ctx context.Context, deps Deps, opts ShowOptions, paths []string, w io.Writer) []FileResult {
+	out := &trackingWriter{w: w}
+	separate := opts.Header && Decorate(opts.Decorations, opts.Terminal)
+	results := make([]FileResult, 0, len(paths))
+	for _, path := range paths {
+		fileOpts := opts
+		fileOpts.Path = path
+		file := &separatedWriter{out: out, pending: separate && out.n > 0}
+		err := RunShowTo(ctx, deps, fileOpts, file)
+		results = append(results, FileResult{Path: path, Err: err})
+		if out.err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// separatedWriter writes the blank line between two files before the first
+// output of the second, ending the first file's last line if it has no
+// newline.
+type separatedWriter struct {
+	out     *trackingWriter
+	pending bool
+}
+
+func (s *separatedWriter) Write(p []byte) (int, error) {
+	if s.pending && len(p) > 0 {
+		s.pending = false
+		separator := "\n"
+		if s.out.last != '\n' {
+			separator = "\n\n"
+		}
+		if _, err := io.WriteString(s.out, separator); err != nil {
+			return 0, err
+		}
+	}
+	return s.out.Write(p)
+}
+
+// fileHeader describes path using its detected file type and, when it is
+// known, the file size.
+func fileHeader(path string, fileType string, size int64, sized bool, color bool) string {
+	name, details := fileHeaderParts(path, fileType, size, sized)
+	if !color {
+		return fmt.Sprintf("File: %s (%s)\n", name, details)
+	}
+	return fmt.Sprintf("\x1b[0m\x1b[1mFile: %s\x1b[0m (%s)\n", name, details)
+}
+
+func fileHeaderText(path string, fileType string, size int64, sized bool) string {
+	name, details := fileHeaderParts(path, fileType, size, sized)
+	return fmt.Sprintf("File: %s (%s)", name, details)
+}
+
+// fileHeaderParts returns the name shown for path and the file type and
+// size that follow it in parentheses.
+func fileHeaderParts(path string, fileType string, size int64, sized bool) (string, string) {
+	name := path
+	if path == StdinPath {
+		name = "<stdin>"
+	}
+	details := fileType
+	if sized {
+		details += ", " + formatSize(size)
+	}
+	return name, details
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
@@ -0,0 +1,95 @@
+package show
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunShowFiles(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: pathReader{"a.go": "package a\n", "b.yaml": "key: value\n"}}
+	results := RunShowFiles(t.Context(), deps, ShowOptions{Header: true}, []string{"a.go", "missing.txt", "b.yaml"})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if results[0].Err != nil || !strings.HasPrefix(string(results[0].Content), "File: a.go (Go, 10 B)\n") {
+		t.Fatalf("expected header for a.go, got %q (err %v)", results[0].Content, results[0].Err)
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "read file") {
+		t.Fatalf("expected read error for missing file, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || !strings.Contains(string(results[2].Content), "1 | ") {
+		t.Fatalf("expected numbered b.yaml output, got %q (err %v)", results[2].Content, results[2].Err)
+	}
+}
+
+func TestRunShowFilesTo(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: pathReader{"a.go": "package a\n", "b.yaml": "key: value\n"}}
+	var b strings.Builder
+	results := RunShowFilesTo(t.Context(), deps, ShowOptions{Header: true}, []string{"a.go", "missing.txt", "b.yaml"}, &b)
+	if len(results) != 3 || results[0].Err != nil || results[1].Err == nil || results[2].Err != nil {
+		t.Fatalf("expected only missing.txt to fail, got %+v", results)
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "File: a.go (Go, 10 B)\n") || !strings.Contains(got, "File: b.yaml (YAML, 11 B)\n") {
+		t.Fatalf("expected both files streamed with headers, got %q", got)
+	}
+
+	b.Reset()
+	unterminated := Deps{FileReader: pathReader{"a.txt": "one", "b.txt": "two\n"}}
+	RunShowFilesTo(t.Context(), unterminated, ShowOptions{Header: true}, []string{"missing", "a.txt", "missing", "b.txt"}, &b)
+	want := "File: a.txt (plaintext, 3 B)\n1 | one\n\nFile: b.txt (plaintext, 4 B)\n1 | two\n"
+	if b.String() != want {
+		t.Fatalf("expected one blank line between the files shown\nwant %q\ngot  %q", want, b.String())
+	}
+
+	b.Reset()
+	out := &trackingWriter{w: &b}
+	out.Write([]byte("no newline"))
+	separated := &separatedWriter{out: out, pending: true}
+	separated.Write([]byte("next\n"))
+	if b.String() != "no newline\n\nnext\n" {
+		t.Fatalf("expected the unterminated line ended before the blank line, got %q", b.String())
+	}
+
+	results = RunShowFilesTo(t.Context(), deps, ShowOptions{}, []string{"a.go", "b.yaml"}, failingWriter{})
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("expected a write error to stop the run, got %+v", results)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestFormatSize(t *testing.T) {
+	cases := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for size, want := range cases {
+		if got := formatSize(size); got != want {
+			t.Fatalf("formatSize(%d): expected %q, got %q", size, want, got)
+		}
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	got := fileHeader("notes (copy).md", "markdown", 3, true, true)
+	want := "\x1b[0m\x1b[1mFile: notes (copy).md\x1b[0m (markdown, 3 B)\n"
+	if got != want {
+		t.Fatalf("expected the whole name in bold, got %q", got)
+	}
+	if got := fileHeader("notes (copy).md", "markdown", 0, false, false); got != "File: notes (copy).md (markdown)\n" {
+		t.Fatalf("expected plain header, got %q", got)
+	}
+}
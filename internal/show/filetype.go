@@ -4,23 +4,13 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
 )
 
-func detectFileTypeFromExtension(path string) string {
-	lexer := lexers.Match(path)
-	if lexer == nil {
-		return "unknown"
-	}
-	lexer = chroma.Coalesce(lexer)
-	if lexer == nil {
-		return "unknown"
-	}
-	if config := lexer.Config(); config != nil && config.Name != "" {
-		return config.Name
-	}
-	return "unknown"
+// IsSupportedFileType reports whether name selects a lexer, as accepted by
+// ShowOptions.FileType.
+func IsSupportedFileType(name string) bool {
+	return lexers.Get(name) != nil
 }
 
 func SupportedFileTypes() []string {
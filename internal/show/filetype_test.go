@@ -5,18 +5,6 @@ import (
 	"testing"
 )
 
-func TestDetectFileTypeFromExtension(t *testing.T) {
-	if got := detectFileTypeFromExtension("main.go"); got == "unknown" {
-		t.Fatalf("expected known file type for .go, got %q", got)
-	}
-}
-
-func TestDetectFileTypeFromExtensionUnknown(t *testing.T) {
-	if got := detectFileTypeFromExtension("file.unknownext"); got != "unknown" {
-		t.Fatalf("expected unknown file type, got %q", got)
-	}
-}
-
 func TestSupportedFileTypes(t *testing.T) {
 	types := SupportedFileTypes()
 	if len(types) == 0 {
@@ -31,3 +19,12 @@ func TestSupportedFileTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestIsSupportedFileType(t *testing.T) {
+	if !IsSupportedFileType("go") {
+		t.Fatal("expected go to be supported")
+	}
+	if IsSupportedFileType("no-such-type") {
+		t.Fatal("expected unknown type to be unsupported")
+	}
+}
@@ -0,0 +1,350 @@
+package show
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotRepository is returned by Git implementations for paths outside a
+// git work tree, or when git is not available at all. RunShowTo shows such
+// files without diff markers.
+var ErrNotRepository = errors.New("not in a git work tree")
+
+// ErrUntracked is returned by Git implementations for files in a work tree
+// that git does not track, which have no diff to show. Like files outside a
+// work tree, they are shown without diff markers, but ShowOptions.Diff
+// refuses them.
+var ErrUntracked = errors.New("file is not tracked by git")
+
+// ErrNoChanges is returned, once the file has been rendered, when
+// ShowOptions.Diff finds no changes against the base revision. The output
+// is then empty apart from any header.
+var ErrNoChanges = errors.New("no changes")
+
+// Git reports how files differ from a revision, for the diff markers in the
+// gutter.
+type Git interface {
+	// Diff returns the unified diff of path without context lines
+	// (git diff -U0), comparing the work tree against rev, or against the
+	// index when rev is empty. It returns ErrUntracked for files git does
+	// not track.
+	Diff(ctx context.Context, path, rev string) ([]byte, error)
+}
+
+// ExecGit implements Git by running the git binary found on PATH.
+type ExecGit struct{}
+
+func (ExecGit) Diff(ctx context.Context, path, rev string) ([]byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(abs)
+	if _, err := runGit(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrNotRepository
+	}
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--no-textconv", "-U0"}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	args = append(args, "--", filepath.Base(abs))
+	diff, err := runGit(ctx, dir, args...)
+	if err != nil || len(diff) > 0 {
+		return diff, err
+	}
+	// git diff says nothing about untracked files either.
+	if _, err := runGit(ctx, dir, "ls-files", "--error-unmatch", "--", filepath.Base(abs)); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrUntracked
+	}
+	return diff, nil
+}
+
+// runGit runs git in dir and returns its output. A failing command's error
+// carries what git wrote to stderr.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, gitError(args[0], err, &stderr)
+	}
+	return out, nil
+}
+
+// gitError describes a failed git command, using what it wrote to stderr
+// when it ran but exited unsuccessfully.
+func gitError(command string, err error, stderr *bytes.Buffer) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && stderr.Len() > 0 {
+		return fmt.Errorf("git %s: %s", command, strings.TrimSpace(stderr.String()))
+	}
+	return fmt.Errorf("git %s: %w", command, err)
+}
+
+// startGit runs git in dir and streams its output. A command that fails
+// reports its error from Read in place of io.EOF, with what git wrote to
+// stderr as for runGit. Closing the stream early stops git.
+func startGit(ctx context.Context, dir string, args ...string) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	out := &gitOutput{cmd: cmd, command: args[0]}
+	cmd.Stderr = &out.stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	out.stdout = stdout
+	return out, nil
+}
+
+// gitOutput is the output of a command started by startGit.
+type gitOutput struct {
+	cmd     *exec.Cmd
+	command string
+	stdout  io.ReadCloser
+	stderr  bytes.Buffer
+	waited  bool
+	err     error
+}
+
+func (o *gitOutput) Read(p []byte) (int, error) {
+	if o.waited {
+		return 0, o.finalErr()
+	}
+	n, err := o.stdout.Read(p)
+	if errors.Is(err, io.EOF) {
+		o.waited = true
+		if waitErr := o.cmd.Wait(); waitErr != nil {
+			o.err = gitError(o.command, waitErr, &o.stderr)
+		}
+		return n, o.finalErr()
+	}
+	return n, err
+}
+
+// finalErr is what Read returns once git has exited.
+func (o *gitOutput) finalErr() error {
+	if o.err != nil {
+		return o.err
+	}
+	return io.EOF
+}
+
+// Close stops git if its output was not read to the end, ignoring the
+// failure that stopping it causes.
+func (o *gitOutput) Close() error {
+	if o.waited {
+		return nil
+	}
+	o.waited = true
+	o.stdout.Close()
+	o.cmd.Wait()
+	return nil
+}
+
+// Diff markers drawn in the gutter.
+const (
+	diffAdded    = '+'
+	diffModified = '~'
+	diffDeleted  = '_'
+)
+
+// Diff marker colours.
+const (
+	diffAddedColor    = "\x1b[32m"
+	diffModifiedColor = "\x1b[33m"
+	diffDeletedColor  = "\x1b[31m"
+)
+
+// lineChanges maps line numbers in the work tree file to their diff marker.
+type lineChanges map[int]rune
+
+// parseDiff reads the hunk headers of a unified diff without context.
+// Lines only in the new file are added and lines replacing old ones are
+// modified. A deletion is marked on the line above it, or on the first line
+// when the start of the file was deleted, unless that line changed too.
+func parseDiff(diff []byte) (lineChanges, error) {
+	changes := lineChanges{}
+	for _, line := range strings.Split(string(diff), "\n") {
+		if !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[3] != "@@" {
+			return nil, fmt.Errorf("invalid hunk header: %s", line)
+		}
+		_, oldCount, err := parseHunkRange(fields[1], "-")
+		if err != nil {
+			return nil, fmt.Errorf("invalid hunk header: %s", line)
+		}
+		start, count, err := parseHunkRange(fields[2], "+")
+		if err != nil {
+			return nil, fmt.Errorf("invalid hunk header: %s", line)
+		}
+		marker := diffModified
+		switch {
+		case count == 0:
+			if _, ok := changes[max(start, 1)]; !ok {
+				changes[max(start, 1)] = diffDeleted
+			}
+			continue
+		case oldCount == 0:
+			marker = diffAdded
+		}
+		for i := start; i < start+count; i++ {
+			changes[i] = marker
+		}
+	}
+	return changes, nil
+}
+
+// parseHunkRange parses a hunk header range such as "-12,3" or "+7", where
+// an omitted count means one line.
+func parseHunkRange(field, sign string) (int, int, error) {
+	value, ok := strings.CutPrefix(field, sign)
+	if !ok {
+		return 0, 0, fmt.Errorf("missing %s", sign)
+	}
+	startText, countText, hasCount := strings.Cut(value, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// gitChanges returns the diff markers for opts.Path, or nil when there is
+// no Git, the path is standard input, or it is outside a work tree or not
+// tracked. With opts.Diff the last two are reported as errors instead.
+func gitChanges(ctx context.Context, git Git, opts ShowOptions) (lineChanges, error) {
+	if git == nil || opts.NoGit || opts.Path == StdinPath {
+		return nil, nil
+	}
+	diff, err := git.Diff(ctx, opts.Path, opts.DiffBase)
+	if (errors.Is(err, ErrNotRepository) || errors.Is(err, ErrUntracked)) && !opts.Diff {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(diff)
+}
+
+// validateDiff rejects options that ShowOptions.Diff cannot be combined
+// with.
+func validateDiff(opts ShowOptions, git Git) error {
+	if opts.DiffContext < 0 {
+		return fmt.Errorf("invalid diff context: %d", opts.DiffContext)
+	}
+	if !opts.Diff {
+		return nil
+	}
+	switch {
+	case git == nil || opts.NoGit:
+		return errors.New("diff output needs git")
+	case opts.Hex:
+		return errors.New("diff output cannot be combined with hex output")
+	case opts.Output == OutputHTML:
+		return errors.New("diff output cannot be combined with html output")
+	case len(opts.LineRanges) > 0:
+		return errors.New("diff output cannot be combined with line ranges")
+	}
+	return nil
+}
+
+// ranges returns the lines within context lines of a change, merging
+// ranges that touch. Without changes it returns a range that selects no
+// line at all.
+func (c lineChanges) ranges(context int) []LineRange {
+	if len(c) == 0 {
+		return []LineRange{{Start: 2, End: 1}}
+	}
+	lines := make([]int, 0, len(c))
+	for line := range c {
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	var ranges []LineRange
+	for _, line := range lines {
+		r := LineRange{Start: max(line-context, 1), End: line + context}
+		if last := len(ranges) - 1; last >= 0 && r.Start <= ranges[last].End+1 {
+			ranges[last].End = r.End
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// writeDivider writes the line that separates hunks in diff output: a
+// vertical ellipsis under the last digit of the line numbers.
+func (n *lineNumberer) writeDivider() error {
+	if n.hide {
+		return nil
+	}
+	mark := "⋮"
+	if !isUTF8Locale() {
+		mark = ":"
+	}
+	column := strings.Repeat(" ", max(n.width-1, 0)) + mark
+	if n.changes != nil {
+		column += "  "
+	}
+	var err error
+	if n.color != "" {
+		_, err = fmt.Fprintf(n.w, "\x1b[0m%s%s %s\x1b[0m\n", n.color, column, n.sep)
+	} else {
+		_, err = fmt.Fprintf(n.w, "%s %s\n", column, n.sep)
+	}
+	return err
+}
+
+// diffColumn returns the diff marker column for the current line, followed
+// by a space, or "" when there is no column.
+func (n *lineNumberer) diffColumn() string {
+	if n.changes == nil {
+		return ""
+	}
+	marker, ok := n.changes[n.line]
+	if !ok {
+		return "  "
+	}
+	if n.color == "" {
+		return string(marker) + " "
+	}
+	return diffMarkerColor(marker) + string(marker) + n.color + " "
+}
+
+func diffMarkerColor(marker rune) string {
+	switch marker {
+	case diffAdded:
+		return diffAddedColor
+	case diffDeleted:
+		return diffDeletedColor
+	default:
+		return diffModifiedColor
+	}
+}
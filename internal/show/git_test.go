@@ -0,0 +1,264 @@
+package show
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubGit returns a fixed diff and records what it was asked for.
+type stubGit struct {
+	diff string
+	err  error
+	rev  *string
+}
+
+func (s stubGit) Diff(_ context.Context, _ string, rev string) ([]byte, error) {
+	if s.rev != nil {
+		*s.rev = rev
+	}
+	return []byte(s.diff), s.err
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := "diff --git a/f b/f\n--- a/f\n+++ b/f\n" +
+		"@@ -0,0 +1,2 @@\n+a\n+b\n" +
+		"@@ -5 +7 @@\n-x\n+y\n" +
+		"@@ -9,2 +10,0 @@\n-gone\n-gone\n" +
+		"@@ -20,3 +20,0 @@ func main() {\n"
+	got, err := parseDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := lineChanges{1: diffAdded, 2: diffAdded, 7: diffModified, 10: diffDeleted, 20: diffDeleted}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	got, err = parseDiff([]byte("@@ -1,2 +0,0 @@\n"))
+	if err != nil || !reflect.DeepEqual(got, lineChanges{1: diffDeleted}) {
+		t.Fatalf("expected deletion at the start marked on line 1, got %v, %v", got, err)
+	}
+
+	if _, err := parseDiff([]byte("@@ -a +1 @@\n")); err == nil {
+		t.Fatal("expected error for invalid hunk header")
+	}
+}
+
+func TestRunShowGitMarkers(t *testing.T) {
+	setPlainEnv(t)
+
+	var rev string
+	deps := Deps{
+		FileReader: stubReader{data: []byte("a\nb\nc\n")},
+		Git:        stubGit{diff: "@@ -1 +1 @@\n@@ -2,0 +3 @@\n", rev: &rev},
+	}
+	opts := ShowOptions{Path: "a.txt", Color: ColorNever, DiffBase: "HEAD~1"}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	sep := lineSeparator()
+	want := "1 ~ " + sep + " a\n2   " + sep + " b\n3 + " + sep + " c\n"
+	if string(result.Content) != want {
+		t.Fatalf("expected diff markers\nwant %q\ngot  %q", want, result.Content)
+	}
+	if rev != "HEAD~1" {
+		t.Fatalf("expected diff against HEAD~1, got %q", rev)
+	}
+
+	for name, opts := range map[string]ShowOptions{
+		"no git":     {Path: "a.txt", Color: ColorNever, NoGit: true},
+		"no gutter":  {Path: "a.txt", Color: ColorNever, Gutter: GutterOptions{Hide: true}},
+		"not a repo": {Path: "a.txt", Color: ColorNever},
+		"untracked":  {Path: "a.txt", Color: ColorNever},
+	} {
+		deps := deps
+		switch name {
+		case "not a repo":
+			deps.Git = stubGit{err: ErrNotRepository}
+		case "untracked":
+			deps.Git = stubGit{err: ErrUntracked}
+		}
+		result, err := RunShow(t.Context(), deps, opts)
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", name, err)
+		}
+		if strings.Contains(string(result.Content), "~") {
+			t.Fatalf("%s: expected no diff markers, got %q", name, result.Content)
+		}
+	}
+
+	deps.Git = stubGit{err: errors.New("git diff: bad revision")}
+	if _, err := RunShow(t.Context(), deps, opts); err == nil {
+		t.Fatal("expected git errors to be reported")
+	}
+}
+
+// initGitRepo creates a repository in a temporary directory with files
+// committed, or skips the test when git is not installed.
+func initGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	git("add", ".")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecGitDiff(t *testing.T) {
+	dir := initGitRepo(t, map[string]string{"main.go": "one\ntwo\nthree\n"})
+	path := filepath.Join(dir, "main.go")
+	writeFile(t, path, "one\n2\nthree\nfour\n")
+
+	var git ExecGit
+	diff, err := git.Diff(t.Context(), path, "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	changes, err := parseDiff(diff)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := (lineChanges{2: diffModified, 4: diffAdded}); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+
+	writeFile(t, path, "one\ntwo\nthree\n")
+	if diff, err := git.Diff(t.Context(), path, ""); err != nil || len(diff) != 0 {
+		t.Fatalf("expected an empty diff for an unchanged file, got %q, %v", diff, err)
+	}
+	untracked := filepath.Join(dir, "new.go")
+	writeFile(t, untracked, "new\n")
+	if _, err := git.Diff(t.Context(), untracked, "HEAD"); !errors.Is(err, ErrUntracked) {
+		t.Fatalf("expected ErrUntracked, got %v", err)
+	}
+
+	if _, err := git.Diff(t.Context(), path, "no-such-rev"); err == nil {
+		t.Fatal("expected error for unknown revision")
+	}
+
+	outside := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, outside, "a\n")
+	if _, err := git.Diff(t.Context(), outside, ""); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestLineChangesRanges(t *testing.T) {
+	changes := lineChanges{2: diffAdded, 3: diffAdded, 9: diffModified, 20: diffDeleted}
+	got := changes.ranges(3)
+	want := []LineRange{{Start: 1, End: 12}, {Start: 17, End: 23}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := changes.ranges(0); len(got) != 3 {
+		t.Fatalf("expected 3 ranges without context, got %v", got)
+	}
+	for line := 1; line < 30; line++ {
+		if lineSelected(lineChanges{}.ranges(3), line) {
+			t.Fatalf("expected no lines selected without changes, got line %d", line)
+		}
+	}
+}
+
+func TestRunShowDiff(t *testing.T) {
+	setPlainEnv(t)
+
+	var rev string
+	var b strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	deps := Deps{
+		FileReader: stubReader{data: []byte(b.String())},
+		Git:        stubGit{diff: "@@ -2 +2 @@\n@@ -8,0 +9 @@\n", rev: &rev},
+	}
+	opts := ShowOptions{Path: "a.txt", Color: ColorNever, Diff: true, DiffContext: 1}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	sep := lineSeparator()
+	want := " 1   " + sep + " line 1\n 2 ~ " + sep + " line 2\n 3   " + sep + " line 3\n" +
+		" :   " + sep + "\n" +
+		" 8   " + sep + " line 8\n 9 + " + sep + " line 9\n10   " + sep + " line 10\n"
+	if string(result.Content) != want {
+		t.Fatalf("expected changed hunks\nwant %q\ngot  %q", want, result.Content)
+	}
+	if rev != "HEAD" {
+		t.Fatalf("expected diff against HEAD by default, got %q", rev)
+	}
+
+	var out strings.Builder
+	deps.Git = stubGit{rev: &rev}
+	err = RunShowTo(t.Context(), deps, opts, &out)
+	if !errors.Is(err, ErrNoChanges) || err.Error() != "no changes against HEAD" {
+		t.Fatalf("expected ErrNoChanges against HEAD, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output for an unchanged file, got %q", out.String())
+	}
+
+	deps.Git = stubGit{err: ErrNotRepository}
+	if _, err := RunShow(t.Context(), deps, opts); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+	deps.Git = stubGit{err: ErrUntracked}
+	if _, err := RunShow(t.Context(), deps, opts); !errors.Is(err, ErrUntracked) {
+		t.Fatalf("expected ErrUntracked, got %v", err)
+	}
+}
+
+func TestValidateDiff(t *testing.T) {
+	git := stubGit{}
+	for name, tt := range map[string]struct {
+		opts ShowOptions
+		git  Git
+	}{
+		"no git":      {ShowOptions{Diff: true}, nil},
+		"disabled":    {ShowOptions{Diff: true, NoGit: true}, git},
+		"hex":         {ShowOptions{Diff: true, Hex: true}, git},
+		"html":        {ShowOptions{Diff: true, Output: OutputHTML}, git},
+		"line ranges": {ShowOptions{Diff: true, LineRanges: []LineRange{{Start: 1}}}, git},
+		"context":     {ShowOptions{DiffContext: -1}, git},
+	} {
+		if err := validateDiff(tt.opts, tt.git); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if err := validateDiff(ShowOptions{Diff: true, DiffContext: 3}, git); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
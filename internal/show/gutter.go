@@ -0,0 +1,437 @@
+package show
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// GutterOptions controls the line-number prefix written before each line.
+// The zero value gives right-aligned numbers starting at 1, the locale's
+// separator and a white gutter.
+type GutterOptions struct {
+	// Hide drops the gutter entirely so output can be copied verbatim.
+	Hide bool
+	// Start is the number shown for the first line of the file; nil
+	// means 1. It must not be negative.
+	Start *int
+	// Separator replaces the locale-dependent │ or | separator.
+	Separator string
+	// Color is a colour name (e.g. "white", "bright-black"), a 0-255
+	// palette index, "#rrggbb", or "none".
+	Color string
+	// MinWidth pads numbers to at least this many columns.
+	MinWidth int
+}
+
+var gutterColorNames = map[string]int{
+	"black":   30,
+	"red":     31,
+	"green":   32,
+	"yellow":  33,
+	"blue":    34,
+	"magenta": 35,
+	"cyan":    36,
+	"white":   37,
+}
+
+// gutterColorEscape returns the escape sequence for a GutterOptions.Color
+// value at the given colour depth. "none" yields an empty sequence.
+func gutterColorEscape(name string, depth string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "":
+		return "\x1b[37m", nil
+	case name == "none":
+		return "", nil
+	case strings.HasPrefix(name, "#"):
+		colour := chroma.ParseColour(name)
+		if !colour.IsSet() || len(name) != 7 {
+			return "", fmt.Errorf("unknown gutter color: %s", name)
+		}
+		return foregroundEscape(colour, depth), nil
+	case name == "gray" || name == "grey":
+		return "\x1b[90m", nil
+	}
+	if code, ok := gutterColorNames[strings.TrimPrefix(name, "bright-")]; ok {
+		if strings.HasPrefix(name, "bright-") {
+			code += 60
+		}
+		return fmt.Sprintf("\x1b[%dm", code), nil
+	}
+	if index, err := strconv.Atoi(name); err == nil && index >= 0 && index <= 255 {
+		if depth == Depth16 && index >= 16 {
+			r, g, b := paletteColour(index)
+			return foregroundEscape(chroma.NewColour(uint8(r), uint8(g), uint8(b)), depth), nil
+		}
+		return fmt.Sprintf("\x1b[38;5;%dm", index), nil
+	}
+	return "", fmt.Errorf("unknown gutter color: %s", name)
+}
+
+// gutterWidth returns the number column width for a file of lines lines.
+func (g GutterOptions) gutterWidth(lines int) int {
+	last := lines + g.start() - 1
+	return max(len(strconv.Itoa(last)), g.MinWidth)
+}
+
+func (g GutterOptions) start() int {
+	if g.Start == nil {
+		return 1
+	}
+	return *g.Start
+}
+
+// validate checks the options that cannot be checked while rendering.
+func (g GutterOptions) validate(depth string) error {
+	if g.Start != nil && *g.Start < 0 {
+		return fmt.Errorf("invalid number start: %d", *g.Start)
+	}
+	if g.MinWidth < 0 {
+		return fmt.Errorf("invalid gutter width: %d", g.MinWidth)
+	}
+	_, err := gutterColorEscape(g.Color, depth)
+	return err
+}
+
+// lineNumberer is an io.Writer that prefixes every line of highlighted
+// output with its line number. Lines may arrive split across any number of
+// writes; escape sequences that precede the first visible byte of a line are
+// held back so the prefix does not reset their colour.
+type lineNumberer struct {
+	ctx         context.Context
+	w           io.Writer
+	width       int
+	line        int
+	started     bool
+	skipping    bool
+	marked      bool
+	pending     strings.Builder
+	hide        bool
+	offset      int
+	sep         string
+	color       string
+	ranges      []LineRange
+	highlights  []LineRange
+	highlightBG string
+	// wrap is WrapCharacter or WrapWord when lines are wrapped at limit
+	// content columns; see wrap.go for the remaining state.
+	wrap        string
+	limit       int
+	prefixWidth int
+	col         int
+	word        strings.Builder
+	wordWidth   int
+	longWord    bool
+	// sgr holds the colours in effect since the formatter's last reset. It
+	// is kept across lines so a token that spans them, such as a block
+	// comment, is restored after each gutter prefix and wrapped row.
+	sgr strings.Builder
+	// tabs is the tab width tabs are expanded to, or zero to pass them
+	// through; tabCol is the content column the next rune starts at.
+	tabs   int
+	tabCol int
+	// showAll draws invisible characters as glyphs in glyphColor; see
+	// showall.go. spaces holds spaces that may turn out to be trailing and
+	// style the formatter's colours.
+	showAll    bool
+	glyphColor string
+	spaces     strings.Builder
+	style      strings.Builder
+	// endings draws each line's ending, which ending records from the
+	// sentinel before the newline; see lineendings.go.
+	endings bool
+	ending  rune
+	// changes holds the git diff markers; when it is not nil the gutter
+	// has a marker column. See git.go.
+	changes lineChanges
+	// divider separates runs of lines that are not adjacent, with shown
+	// the last line written.
+	divider bool
+	shown   int
+	// err is the first write error. Chroma formatters ignore write errors,
+	// so it is kept to stop rendering between chunks.
+	err error
+}
+
+func newLineNumberer(w io.Writer, width int) *lineNumberer {
+	n := &lineNumberer{
+		w:     w,
+		width: width,
+		line:  1,
+		sep:   lineSeparator(),
+	}
+	if !noColor() {
+		n.color = "\x1b[37m"
+	}
+	return n
+}
+
+// configure applies gutter options on top of the defaults. Without color
+// the gutter and line highlights are plain text.
+func (n *lineNumberer) configure(g GutterOptions, color bool, depth string) error {
+	n.hide = g.Hide
+	n.offset = g.start() - 1
+	if g.Separator != "" {
+		n.sep = g.Separator
+	}
+	escape, err := gutterColorEscape(g.Color, depth)
+	if err != nil {
+		return err
+	}
+	n.color = ""
+	if color {
+		n.color = escape
+	}
+	return nil
+}
+
+func (n *lineNumberer) Write(p []byte) (int, error) {
+	if n.err != nil {
+		return 0, n.err
+	}
+	written, err := n.write(p)
+	if err != nil {
+		n.err = err
+	}
+	return written, err
+}
+
+func (n *lineNumberer) write(p []byte) (int, error) {
+	s := string(p)
+	for s != "" {
+		if !n.started {
+			if n.ctx != nil {
+				if err := canceled(n.ctx, "number lines"); err != nil {
+					return 0, err
+				}
+			}
+			esc := leadingEscapes(s)
+			n.pending.WriteString(esc)
+			s = s[len(esc):]
+			if s == "" {
+				break
+			}
+			if !lineSelected(n.ranges, n.line) {
+				n.trackEscapes(n.pending.String())
+				n.pending.Reset()
+				n.started = true
+				n.skipping = true
+			} else if err := n.startLine(); err != nil {
+				return 0, err
+			}
+		}
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			if err := n.writeText(s); err != nil {
+				return 0, err
+			}
+			break
+		}
+		if err := n.writeText(s[:i]); err != nil {
+			return 0, err
+		}
+		if err := n.endLine(); err != nil {
+			return 0, err
+		}
+		s = s[i+1:]
+	}
+	return len(p), nil
+}
+
+// Done reports whether every line the ranges can select has been written.
+func (n *lineNumberer) Done() bool {
+	last := lastSelectedLine(n.ranges)
+	return last > 0 && n.line > last
+}
+
+// Flush writes escape sequences still held back for a line that never
+// started, and closes the background of an unterminated highlighted line.
+func (n *lineNumberer) Flush() error {
+	if n.showAll && n.started && !n.skipping {
+		if err := n.put(n.endVisibleLine()); err != nil {
+			return err
+		}
+	}
+	if err := n.finishWrap(); err != nil {
+		return err
+	}
+	if n.started && n.marked && n.highlightBG != "" {
+		if _, err := io.WriteString(n.w, "\x1b[K\x1b[0m"); err != nil {
+			return err
+		}
+		n.marked = false
+	}
+	if n.pending.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(n.w, n.pending.String())
+	n.pending.Reset()
+	return err
+}
+
+func (n *lineNumberer) startLine() error {
+	n.started = true
+	n.marked = len(n.highlights) > 0 && lineSelected(n.highlights, n.line)
+	if n.divider && n.shown > 0 && n.line > n.shown+1 {
+		if err := n.writeDivider(); err != nil {
+			return err
+		}
+	}
+	n.shown = n.line
+	if err := n.writePrefix(); err != nil {
+		return err
+	}
+	if n.marked && n.highlightBG != "" {
+		if _, err := io.WriteString(n.w, n.highlightBG); err != nil {
+			return err
+		}
+	}
+	// The prefix resets colours, so restore those of a token that began on
+	// an earlier line, such as a block comment.
+	if err := n.emit(n.sgr.String()); err != nil {
+		return err
+	}
+	if n.pending.Len() > 0 {
+		pending := n.pending.String()
+		n.pending.Reset()
+		return n.writeText(pending)
+	}
+	return nil
+}
+
+func (n *lineNumberer) writePrefix() error {
+	if n.hide {
+		return nil
+	}
+	sep := n.sep
+	if n.marked {
+		sep = highlightMarker()
+	}
+	number := n.line + n.offset
+	var err error
+	if n.color != "" {
+		_, err = fmt.Fprintf(n.w, "\x1b[0m%s%*d %s%s\x1b[0m ", n.color, n.width, number, n.diffColumn(), sep)
+	} else {
+		_, err = fmt.Fprintf(n.w, "%*d %s%s ", n.width, number, n.diffColumn(), sep)
+	}
+	return err
+}
+
+// writeText writes part of the current line, expanding tabs or drawing
+// invisible characters, and wrapping it when a wrap limit is set.
+func (n *lineNumberer) writeText(s string) error {
+	s = n.takeLineEnding(s)
+	if n.skipping {
+		n.trackEscapes(s)
+		return nil
+	}
+	if s == "" {
+		return nil
+	}
+	if n.showAll {
+		s = n.visible(s)
+	} else {
+		s = n.expandTabs(s)
+	}
+	return n.put(s)
+}
+
+// put writes text that is ready to be displayed, wrapping it when a wrap
+// limit is set.
+func (n *lineNumberer) put(s string) error {
+	if n.limit > 0 {
+		return n.wrapText(s)
+	}
+	n.trackEscapes(s)
+	return n.emit(s)
+}
+
+// emit writes s. On a highlighted line the background is restored after
+// every reset the formatter emits.
+func (n *lineNumberer) emit(s string) error {
+	if s == "" {
+		return nil
+	}
+	if !n.marked || n.highlightBG == "" {
+		_, err := io.WriteString(n.w, s)
+		return err
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			b.WriteString(s[i : i+l])
+			if isReset(s[i : i+l]) {
+				b.WriteString(n.highlightBG)
+			}
+			i += l
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	_, err := io.WriteString(n.w, b.String())
+	return err
+}
+
+func (n *lineNumberer) endLine() error {
+	if !n.skipping {
+		if n.showAll {
+			if err := n.put(n.endVisibleLine()); err != nil {
+				return err
+			}
+		}
+		if n.endings {
+			if err := n.put(n.lineEndingGlyph()); err != nil {
+				return err
+			}
+		}
+		if err := n.finishWrap(); err != nil {
+			return err
+		}
+		end := "\n"
+		if n.marked && n.highlightBG != "" {
+			end = "\x1b[K\x1b[0m\n"
+		}
+		if _, err := io.WriteString(n.w, end); err != nil {
+			return err
+		}
+	}
+	n.line++
+	n.started = false
+	n.skipping = false
+	n.marked = false
+	n.col = 0
+	n.tabCol = 0
+	n.ending = 0
+	n.style.Reset()
+	return nil
+}
+
+// highlightMarker replaces the separator on highlighted lines.
+func highlightMarker() string {
+	if isUTF8Locale() {
+		return "▶"
+	}
+	return ">"
+}
+
+// lineHighlightBackground returns the escape sequence for the style's
+// LineHighlight background, or a lightened editor background when the style
+// does not define one.
+func lineHighlightBackground(style *chroma.Style, depth string) string {
+	colour := style.Get(chroma.LineHighlight).Background
+	if !colour.IsSet() {
+		colour = style.Get(chroma.Background).Background
+		if !colour.IsSet() {
+			colour = chroma.MustParseColour("#3e4451")
+		} else {
+			colour = colour.Brighten(0.15)
+		}
+	}
+	return backgroundEscape(colour, depth)
+}
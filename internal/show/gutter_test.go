@@ -0,0 +1,130 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGutterColorEscape(t *testing.T) {
+	cases := map[string]string{
+		"":             "\x1b[37m",
+		"none":         "",
+		"green":        "\x1b[32m",
+		"Bright-Black": "\x1b[90m",
+		"gray":         "\x1b[90m",
+		"244":          "\x1b[38;5;244m",
+		"#ff8000":      "\x1b[38;2;255;128;0m",
+	}
+	for name, want := range cases {
+		got, err := gutterColorEscape(name, DepthTrueColor)
+		if err != nil {
+			t.Fatalf("gutterColorEscape(%q): unexpected error %v", name, err)
+		}
+		if got != want {
+			t.Fatalf("gutterColorEscape(%q): expected %q, got %q", name, want, got)
+		}
+	}
+
+	for _, name := range []string{"mauve", "256", "#fff", "#zzzzzz"} {
+		if _, err := gutterColorEscape(name, DepthTrueColor); err == nil || !strings.Contains(err.Error(), "unknown gutter color") {
+			t.Fatalf("gutterColorEscape(%q): expected unknown gutter color error, got %v", name, err)
+		}
+	}
+}
+
+func startAt(n int) *int {
+	return &n
+}
+
+func TestGutterWidth(t *testing.T) {
+	if got := (GutterOptions{}).gutterWidth(9); got != 1 {
+		t.Fatalf("expected width 1, got %d", got)
+	}
+	if got := (GutterOptions{Start: startAt(95)}).gutterWidth(9); got != 3 {
+		t.Fatalf("expected start offset to widen the gutter to 3, got %d", got)
+	}
+	if got := (GutterOptions{MinWidth: 4}).gutterWidth(9); got != 4 {
+		t.Fatalf("expected minimum width 4, got %d", got)
+	}
+}
+
+func TestRunShowGutterOptions(t *testing.T) {
+	setPlainEnv(t)
+	deps := Deps{FileReader: stubReader{data: []byte("one\ntwo\n")}}
+
+	cases := []struct {
+		name   string
+		gutter GutterOptions
+		want   string
+	}{
+		{"hidden", GutterOptions{Hide: true}, "one\ntwo\n"},
+		{"start", GutterOptions{Start: startAt(99)}, " 99 | one\n100 | two\n"},
+		{"start at zero", GutterOptions{Start: startAt(0)}, "0 | one\n1 | two\n"},
+		{"separator", GutterOptions{Separator: ":"}, "1 : one\n2 : two\n"},
+		{"min width", GutterOptions{MinWidth: 3}, "  1 | one\n  2 | two\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Gutter: tc.gutter})
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			if got := stripEscapes(string(result.Content)); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	_, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Gutter: GutterOptions{Start: startAt(-1)}})
+	if err == nil || !strings.Contains(err.Error(), "invalid number start") {
+		t.Fatalf("expected negative start error, got %v", err)
+	}
+}
+
+func TestRunShowGutterColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	deps := Deps{FileReader: stubReader{data: []byte("one\n")}}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Gutter: GutterOptions{Color: "cyan"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(string(result.Content), "\x1b[0m\x1b[36m1 ") {
+		t.Fatalf("expected cyan gutter, got %q", result.Content)
+	}
+
+	result, err = RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Gutter: GutterOptions{Color: "none"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(string(result.Content), "1 ") {
+		t.Fatalf("expected uncoloured gutter, got %q", result.Content)
+	}
+
+	_, err = RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Gutter: GutterOptions{Color: "mauve"}})
+	if err == nil || !strings.Contains(err.Error(), "unknown gutter color") {
+		t.Fatalf("expected unknown gutter color error, got %v", err)
+	}
+}
+
+func TestRunShowGutterColorNoneHighlight(t *testing.T) {
+	setPlainEnv(t)
+	t.Setenv("NO_COLOR", "")
+	deps := Deps{FileReader: stubReader{data: []byte("a\nb\n")}}
+
+	opts := ShowOptions{
+		Path:           "a.txt",
+		Color:          "always",
+		Gutter:         GutterOptions{Color: "none"},
+		HighlightLines: []LineRange{{Start: 2, End: 2}},
+	}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	lines := strings.Split(string(result.Content), "\n")
+	bg := lineHighlightBackground(selectStyle(""), colorDepth(""))
+	if !strings.Contains(lines[1], "2 >") || !strings.Contains(lines[1], bg) {
+		t.Fatalf("expected background on line 2 without gutter colour, got %q", lines[1])
+	}
+}
@@ -0,0 +1,194 @@
+package show
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrBinary is returned for binary input unless ShowOptions.Hex asks for a
+// hexdump.
+var ErrBinary = errors.New("file looks binary")
+
+// binarySampleSize is how much of the input isBinary looks at.
+const binarySampleSize = 8 * 1024
+
+// binaryInvalidRatio is the share of bytes outside valid UTF-8 above which
+// input is treated as binary. Legacy 8-bit text stays well below it.
+const binaryInvalidRatio = 0.3
+
+// isBinary reports whether sample, the start of the input, looks like binary
+// data: it contains a NUL byte or is mostly not UTF-8.
+func isBinary(sample []byte) bool {
+	if len(sample) > binarySampleSize {
+		sample = sample[:binarySampleSize]
+		sample = sample[:incompleteRuneStart(sample)]
+	}
+	if len(sample) == 0 {
+		return false
+	}
+	invalid := 0
+	for i := 0; i < len(sample); {
+		if sample[i] == 0 {
+			return true
+		}
+		r, size := utf8.DecodeRune(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			invalid++
+		}
+		i += size
+	}
+	return float64(invalid)/float64(len(sample)) > binaryInvalidRatio
+}
+
+// hexRowSize is the number of bytes on each hexdump row.
+const hexRowSize = 16
+
+// Hexdump byte colours, by kind of byte.
+const (
+	hexNullColor       = "\x1b[90m"
+	hexPrintableColor  = "\x1b[36m"
+	hexWhitespaceColor = "\x1b[32m"
+	hexControlColor    = "\x1b[35m"
+	hexHighColor       = "\x1b[33m"
+)
+
+// hexDumper is an io.Writer that renders its input as rows of offset, hex
+// bytes and printable ASCII. The offset column is drawn like the line
+// number gutter.
+type hexDumper struct {
+	w      io.Writer
+	offset int64
+	row    []byte
+	hide   bool
+	sep    string
+	// gutter is the gutter colour; bytes are coloured when color is set.
+	gutter string
+	color  bool
+}
+
+func newHexDumper(w io.Writer, g GutterOptions, color bool, depth string) (*hexDumper, error) {
+	h := &hexDumper{w: w, hide: g.Hide, sep: lineSeparator(), color: color}
+	if g.Separator != "" {
+		h.sep = g.Separator
+	}
+	if color {
+		escape, err := gutterColorEscape(g.Color, depth)
+		if err != nil {
+			return nil, err
+		}
+		h.gutter = escape
+	}
+	return h, nil
+}
+
+func (h *hexDumper) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := min(hexRowSize-len(h.row), len(p))
+		h.row = append(h.row, p[:n]...)
+		p = p[n:]
+		if len(h.row) == hexRowSize {
+			if err := h.writeRow(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush writes the last, partial row.
+func (h *hexDumper) Flush() error {
+	if len(h.row) == 0 {
+		return nil
+	}
+	return h.writeRow()
+}
+
+func (h *hexDumper) writeRow() error {
+	var b strings.Builder
+	if !h.hide {
+		if h.gutter != "" {
+			fmt.Fprintf(&b, "\x1b[0m%s%08x %s\x1b[0m ", h.gutter, h.offset, h.sep)
+		} else {
+			fmt.Fprintf(&b, "%08x %s ", h.offset, h.sep)
+		}
+	}
+	current := ""
+	setColor := func(escape string) {
+		if h.color && escape != current {
+			b.WriteString(escape)
+			current = escape
+		}
+	}
+	for i := 0; i < hexRowSize; i++ {
+		if i == hexRowSize/2 {
+			b.WriteByte(' ')
+		}
+		if i >= len(h.row) {
+			if current != "" {
+				b.WriteString("\x1b[0m")
+				current = ""
+			}
+			b.WriteString("   ")
+			continue
+		}
+		setColor(hexByteColor(h.row[i]))
+		fmt.Fprintf(&b, "%02x ", h.row[i])
+	}
+	if current != "" {
+		b.WriteString("\x1b[0m")
+		current = ""
+	}
+	if h.gutter != "" {
+		fmt.Fprintf(&b, "%s%s\x1b[0m ", h.gutter, h.sep)
+	} else {
+		fmt.Fprintf(&b, "%s ", h.sep)
+	}
+	for _, c := range h.row {
+		setColor(hexByteColor(c))
+		if c >= 0x20 && c < 0x7f {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	if current != "" {
+		b.WriteString("\x1b[0m")
+	}
+	b.WriteByte('\n')
+
+	h.offset += int64(len(h.row))
+	h.row = h.row[:0]
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func hexByteColor(c byte) string {
+	switch {
+	case c == 0:
+		return hexNullColor
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+		return hexWhitespaceColor
+	case c > 0x20 && c < 0x7f:
+		return hexPrintableColor
+	case c < 0x80:
+		return hexControlColor
+	default:
+		return hexHighColor
+	}
+}
+
+// runHex renders the input as a hexdump.
+func runHex(w io.Writer, opts ShowOptions, chunks *chunkReader, first []byte, color bool, depth string) error {
+	dump, err := newHexDumper(w, opts.Gutter, color, depth)
+	if err != nil {
+		return err
+	}
+	if err := copyChunks(dump, chunks, first); err != nil {
+		return err
+	}
+	return dump.Flush()
+}
@@ -0,0 +1,106 @@
+package show
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   bool
+	}{
+		{"", false},
+		{"plain text\n", false},
+		{"naïve café\n", false},
+		{"caf\xe9 cr\xe8me br\xfbl\xe9e\n", false},
+		{"text\x00more", true},
+		{"\x89\xfe\xc3\x28\xa0\xa1\xe2\x28\xa1", true},
+	}
+	for _, tt := range tests {
+		if got := isBinary([]byte(tt.sample)); got != tt.want {
+			t.Fatalf("isBinary(%q) = %v, want %v", tt.sample, got, tt.want)
+		}
+	}
+}
+
+func TestHexDumper(t *testing.T) {
+	setPlainEnv(t)
+
+	var b strings.Builder
+	dump, err := newHexDumper(&b, GutterOptions{}, false, DepthTrueColor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{"\x7fELF\x00\x01", "0123456789abcdefXY"} {
+		if _, err := dump.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dump.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "00000000 | 7f 45 4c 46 00 01 30 31  32 33 34 35 36 37 38 39 | .ELF..0123456789\n" +
+		"00000010 | 61 62 63 64 65 66 58 59                          | abcdefXY\n"
+	if b.String() != want {
+		t.Fatalf("expected %q, got %q", want, b.String())
+	}
+}
+
+func TestHexDumperColor(t *testing.T) {
+	setPlainEnv(t)
+
+	var b strings.Builder
+	dump, err := newHexDumper(&b, GutterOptions{Hide: true}, true, DepthTrueColor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dump.Write([]byte("a\x00")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dump.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := hexPrintableColor + "61 " + hexNullColor + "00 \x1b[0m" + strings.Repeat("   ", 6) + " " + strings.Repeat("   ", 8) +
+		"\x1b[37m|\x1b[0m " + hexPrintableColor + "a" + hexNullColor + ".\x1b[0m\n"
+	if b.String() != want {
+		t.Fatalf("expected %q, got %q", want, b.String())
+	}
+}
+
+func TestRunShowBinary(t *testing.T) {
+	setPlainEnv(t)
+	deps := Deps{FileReader: stubReader{data: []byte("\x00\x01\x02binary")}}
+
+	_, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.bin"})
+	if !errors.Is(err, ErrBinary) {
+		t.Fatalf("expected ErrBinary, got %v", err)
+	}
+
+	_, err = RunShow(t.Context(), deps, ShowOptions{Path: "a.bin", Decorations: DecorationsNever, Terminal: true, Color: ColorNever})
+	if !errors.Is(err, ErrBinary) {
+		t.Fatalf("expected ErrBinary on a terminal, got %v", err)
+	}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.bin", Decorations: DecorationsNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if string(result.Content) != "\x00\x01\x02binary" {
+		t.Fatalf("expected undecorated output to be copied through, got %q", result.Content)
+	}
+
+	result, err = RunShow(t.Context(), deps, ShowOptions{Path: "a.bin", Hex: true, Header: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := "File: a.bin (binary, 9 B)\n00000000 | 00 01 02 62 69 6e 61 72  79 " + strings.Repeat("   ", 7) + "| ...binary\n"
+	if string(result.Content) != want {
+		t.Fatalf("expected %q, got %q", want, result.Content)
+	}
+
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.bin", Hex: true, Output: OutputHTML}); err == nil {
+		t.Fatal("expected error for hex html output")
+	}
+}
@@ -1,34 +1,16 @@
 package show
 
 import (
-	"bytes"
-	"fmt"
-
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
-	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 )
 
-func highlightContent(path string, content string, fileType string, theme string) (string, error) {
-	var lexer chroma.Lexer
-	if fileType != "" {
-		lexer = lexers.Get(fileType)
-		if lexer == nil {
-			return "", fmt.Errorf("unknown file type: %s", fileType)
-		}
-	} else {
-		lexer = lexers.Match(path)
-		if lexer == nil {
-			lexer = lexers.Analyse(content)
-		}
-	}
-	if lexer == nil {
-		lexer = lexers.Fallback
+func selectFormatter(depth string, color bool) (chroma.Formatter, error) {
+	formatter := formatters.Get(formatterName(depth, color))
+	if formatter == nil {
+		formatter = formatters.Get("terminal16m")
 	}
-	lexer = chromaCoalesce(lexer)
-
-	formatter := formatters.Get("terminal16m")
 	if formatter == nil {
 		formatter = formatters.Get("terminal256")
 	}
@@ -36,9 +18,12 @@ func highlightContent(path string, content string, fileType string, theme string
 		formatter = formatters.Get("terminal")
 	}
 	if formatter == nil {
-		return "", ErrNoFormatter
+		return nil, ErrNoFormatter
 	}
+	return formatter, nil
+}
 
+func selectStyle(theme string) *chroma.Style {
 	if theme == "" {
 		theme = "onedark"
 	}
@@ -46,17 +31,7 @@ func highlightContent(path string, content string, fileType string, theme string
 	if style == nil {
 		style = styles.Fallback
 	}
-
-	iterator, err := lexer.Tokenise(nil, content)
-	if err != nil {
-		return "", err
-	}
-
-	var buf bytes.Buffer
-	if err := formatter.Format(&buf, style, iterator); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return style
 }
 
 var ErrNoFormatter = errNoFormatter{}
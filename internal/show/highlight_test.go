@@ -3,20 +3,28 @@ package show
 import (
 	"strings"
 	"testing"
+
+	"github.com/alecthomas/chroma/v2/styles"
 )
 
-func TestHighlightContentProducesANSI(t *testing.T) {
-	out, err := highlightContent("file.go", "package main\n", "", "")
-	if err != nil {
-		t.Fatalf("highlightContent error: %v", err)
-	}
-	if !strings.Contains(out, "\x1b[") {
-		t.Fatalf("expected ANSI output, got %q", out)
+func TestRunShowProducesANSI(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n")}}
+	for _, depth := range []string{Depth16, Depth256, DepthTrueColor} {
+		result, err := RunShow(t.Context(), deps, ShowOptions{Path: "file.go", Color: ColorAlways, ColorDepth: depth})
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", depth, err)
+		}
+		if !strings.Contains(string(result.Content), "\x1b[") {
+			t.Fatalf("%s: expected ANSI output, got %q", depth, result.Content)
+		}
 	}
 }
 
-func TestHighlightContentUnknownFileType(t *testing.T) {
-	_, err := highlightContent("file.go", "package main\n", "nope-not-a-lexer", "")
+func TestRunShowUnknownFileType(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n")}}
+	_, err := RunShow(t.Context(), deps, ShowOptions{Path: "file.go", FileType: "nope-not-a-lexer"})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -25,12 +33,11 @@ func TestHighlightContentUnknownFileType(t *testing.T) {
 	}
 }
 
-func TestHighlightContentUnknownTheme(t *testing.T) {
-	out, err := highlightContent("file.go", "package main\n", "", "not-a-theme")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestSelectStyleFallback(t *testing.T) {
+	if got := selectStyle("not-a-theme"); got != styles.Fallback {
+		t.Fatalf("expected fallback style, got %s", got.Name)
 	}
-	if !strings.Contains(out, "\x1b[") {
-		t.Fatalf("expected ANSI output, got %q", out)
+	if got := selectStyle(""); got.Name != "onedark" {
+		t.Fatalf("expected onedark by default, got %s", got.Name)
 	}
 }
@@ -0,0 +1,123 @@
+package show
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/alecthomas/chroma/v2"
+	htmlformatter "github.com/alecthomas/chroma/v2/formatters/html"
+)
+
+// Output formats accepted by ShowOptions.Output.
+const (
+	OutputTerminal = "terminal"
+	OutputHTML     = "html"
+)
+
+// HTMLOptions controls OutputHTML rendering.
+type HTMLOptions struct {
+	// Classes emits CSS classes plus a stylesheet instead of inline styles.
+	Classes bool
+	// Standalone wraps the snippet in a complete HTML document.
+	Standalone bool
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputTerminal, OutputHTML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %s (want terminal or html)", output)
+	}
+}
+
+// renderHTML formats content with Chroma's HTML formatter. Line numbers link
+// to #L<n> anchors, and the theme drives both inline styles and the
+// generated stylesheet.
+func renderHTML(ctx context.Context, w io.Writer, opts ShowOptions, lexer chroma.Lexer, style *chroma.Style, content string) error {
+	iterator, err := lexer.Tokenise(nil, content)
+	if err != nil {
+		return err
+	}
+	var cancelErr error
+	tokens := contextIterator(ctx, iterator, &cancelErr).Tokens()
+	if cancelErr != nil {
+		return cancelErr
+	}
+
+	lines := chroma.SplitTokensIntoLines(tokens)
+	first, last, err := htmlLineSpan(opts.LineRanges, len(lines))
+	if err != nil {
+		return err
+	}
+	offset := opts.Gutter.start() - 1
+
+	var highlights [][2]int
+	for _, r := range opts.HighlightLines {
+		end := r.End
+		if end == 0 {
+			end = len(lines)
+		}
+		highlights = append(highlights, [2]int{r.Start + offset, end + offset})
+	}
+
+	options := []htmlformatter.Option{
+		htmlformatter.WithClasses(opts.HTML.Classes),
+		htmlformatter.Standalone(opts.HTML.Standalone),
+		htmlformatter.WithLineNumbers(!opts.Gutter.Hide),
+		htmlformatter.WithLinkableLineNumbers(true, "L"),
+		htmlformatter.BaseLineNumber(first + offset),
+		htmlformatter.HighlightLines(highlights),
+	}
+	if opts.Tabs > 0 {
+		options = append(options, htmlformatter.TabWidth(opts.Tabs))
+	}
+	formatter := htmlformatter.New(options...)
+	if opts.HTML.Classes && !opts.HTML.Standalone {
+		if _, err := io.WriteString(w, "<style>\n"); err != nil {
+			return err
+		}
+		if err := formatter.WriteCSS(w, style); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "</style>\n"); err != nil {
+			return err
+		}
+	}
+
+	var selected []chroma.Token
+	for _, line := range lines[first-1 : last] {
+		selected = append(selected, line...)
+	}
+	return formatter.Format(w, style, chroma.Literator(selected...))
+}
+
+// htmlLineSpan returns the first and last line to render. HTML line numbers
+// count up from a single base, so only one contiguous range is supported.
+func htmlLineSpan(ranges []LineRange, lines int) (int, int, error) {
+	if lines == 0 {
+		return 1, 0, nil
+	}
+	if len(ranges) == 0 {
+		return 1, lines, nil
+	}
+	if len(ranges) > 1 {
+		return 0, 0, fmt.Errorf("html output supports a single line range")
+	}
+	first := max(ranges[0].Start, 1)
+	last := ranges[0].End
+	if last == 0 || last > lines {
+		last = lines
+	}
+	if first > last {
+		return 1, 0, nil
+	}
+	return first, last, nil
+}
+
+// htmlComment renders a header or debug line without disturbing the markup.
+func htmlComment(line string) string {
+	return "<!-- " + html.EscapeString(line) + " -->\n"
+}
@@ -0,0 +1,75 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunShowHTML(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n\nfunc main() {}\n")}}
+	opts := ShowOptions{Path: "main.go", Output: OutputHTML, HighlightLines: []LineRange{{Start: 3, End: 3}}}
+
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := string(result.Content)
+	if !strings.Contains(got, "<pre") || strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected html without ANSI escapes, got %q", got)
+	}
+	if !strings.Contains(got, `id="L3"`) || !strings.Contains(got, `href="#L3"`) {
+		t.Fatalf("expected linkable line numbers, got %q", got)
+	}
+	if !strings.Contains(got, "style=\"") {
+		t.Fatalf("expected inline styles, got %q", got)
+	}
+}
+
+func TestRunShowHTMLClassesAndStandalone(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n")}}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Output: OutputHTML, HTML: HTMLOptions{Classes: true}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := string(result.Content)
+	if !strings.HasPrefix(got, "<style>\n") || !strings.Contains(got, `class="`) {
+		t.Fatalf("expected stylesheet and classes, got %q", got)
+	}
+
+	result, err = RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Output: OutputHTML, HTML: HTMLOptions{Classes: true, Standalone: true}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got = string(result.Content)
+	if !strings.HasPrefix(got, "<html>") || strings.Count(got, "<style") != 1 {
+		t.Fatalf("expected a standalone document with one stylesheet, got %q", got)
+	}
+}
+
+func TestRunShowHTMLLineRange(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("a\nb\nc\nd\n")}}
+	opts := ShowOptions{Path: "a.txt", Output: OutputHTML, LineRanges: []LineRange{{Start: 2, End: 3}}, Gutter: GutterOptions{Start: startAt(10)}}
+
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := string(result.Content)
+	if !strings.Contains(got, `id="L11"`) || !strings.Contains(got, `id="L12"`) || strings.Contains(got, `id="L10"`) || strings.Contains(got, `id="L13"`) {
+		t.Fatalf("expected lines 11 and 12 only, got %q", got)
+	}
+
+	opts.LineRanges = append(opts.LineRanges, LineRange{Start: 4})
+	if _, err := RunShow(t.Context(), deps, opts); err == nil || !strings.Contains(err.Error(), "single line range") {
+		t.Fatalf("expected single range error, got %v", err)
+	}
+}
+
+func TestRunShowUnknownOutput(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("ok\n")}}
+	_, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Output: "pdf"})
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Fatalf("expected unknown output format error, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package show
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Line ending conventions, as reported in debug output.
+const (
+	LineEndingsNone  = "none"
+	LineEndingsLF    = "lf"
+	LineEndingsCRLF  = "crlf"
+	LineEndingsCR    = "cr"
+	LineEndingsMixed = "mixed"
+)
+
+// Lexers only split lines on LF, so CRLF and lone CR endings are rewritten
+// to LF before tokenising. When the endings are to be drawn, a sentinel
+// noncharacter before the LF records what the ending was.
+const (
+	crlfSentinel = '\uFDD0'
+	crSentinel   = '\uFDD2'
+)
+
+// lineEndingCounts tallies the line endings seen in a stream.
+type lineEndingCounts struct {
+	lf, crlf, cr int
+	// pendingCR is set when the data so far ends with a CR that may be the
+	// first half of a CRLF.
+	pendingCR bool
+}
+
+// add counts the endings in the next piece of data.
+func (c *lineEndingCounts) add(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	if c.pendingCR {
+		c.pendingCR = false
+		if data[0] == '\n' {
+			c.crlf++
+			data = data[1:]
+		} else {
+			c.cr++
+		}
+	}
+	crlf := bytes.Count(data, []byte("\r\n"))
+	c.crlf += crlf
+	c.lf += bytes.Count(data, []byte("\n")) - crlf
+	c.cr += bytes.Count(data, []byte("\r")) - crlf
+	if bytes.HasSuffix(data, []byte("\r")) {
+		c.cr--
+		c.pendingCR = true
+	}
+}
+
+// total is the number of line endings, counting a final CR.
+func (c *lineEndingCounts) total() int {
+	total := c.lf + c.crlf + c.cr
+	if c.pendingCR {
+		total++
+	}
+	return total
+}
+
+// convention names the line endings used, or LineEndingsMixed when there is
+// more than one kind.
+func (c *lineEndingCounts) convention() string {
+	cr := c.cr
+	if c.pendingCR {
+		cr++
+	}
+	kinds := 0
+	name := LineEndingsNone
+	for _, kind := range []struct {
+		count int
+		name  string
+	}{{c.lf, LineEndingsLF}, {c.crlf, LineEndingsCRLF}, {cr, LineEndingsCR}} {
+		if kind.count > 0 {
+			kinds++
+			name = kind.name
+		}
+	}
+	if kinds > 1 {
+		return LineEndingsMixed
+	}
+	return name
+}
+
+// describe is convention with the count of each kind when they are mixed.
+func (c *lineEndingCounts) describe() string {
+	name := c.convention()
+	if name != LineEndingsMixed {
+		return name
+	}
+	cr := c.cr
+	if c.pendingCR {
+		cr++
+	}
+	return fmt.Sprintf("%s (lf %d, crlf %d, cr %d)", name, c.lf, c.crlf, cr)
+}
+
+// lineEndingNormalizer rewrites CRLF and CR line endings to LF, one chunk
+// at a time. With mark set, each CRLF or CR ending is preceded by its
+// sentinel.
+type lineEndingNormalizer struct {
+	mark bool
+	// pendingCR holds back a CR that ended the previous chunk until the
+	// next one shows whether it starts a CRLF.
+	pendingCR bool
+}
+
+// normalize returns chunk with its line endings rewritten. last says
+// whether this is the final chunk, so a trailing CR need not be held back.
+func (n *lineEndingNormalizer) normalize(chunk []byte, last bool) []byte {
+	if !n.pendingCR && bytes.IndexByte(chunk, '\r') < 0 {
+		return chunk
+	}
+	out := make([]byte, 0, len(chunk)+8)
+	i := 0
+	if n.pendingCR {
+		n.pendingCR = false
+		if len(chunk) > 0 && chunk[0] == '\n' {
+			out = n.appendEnding(out, crlfSentinel)
+			i = 1
+		} else {
+			out = n.appendEnding(out, crSentinel)
+		}
+	}
+	for ; i < len(chunk); i++ {
+		c := chunk[i]
+		if c != '\r' {
+			out = append(out, c)
+			continue
+		}
+		switch {
+		case i+1 < len(chunk) && chunk[i+1] == '\n':
+			out = n.appendEnding(out, crlfSentinel)
+			i++
+		case i+1 == len(chunk) && !last:
+			n.pendingCR = true
+		default:
+			out = n.appendEnding(out, crSentinel)
+		}
+	}
+	return out
+}
+
+func (n *lineEndingNormalizer) appendEnding(out []byte, sentinel rune) []byte {
+	if n.mark {
+		out = append(out, string(sentinel)...)
+	}
+	return append(out, '\n')
+}
+
+// normalizeLineEndings rewrites every CRLF and CR line ending in data to LF.
+func normalizeLineEndings(data []byte) []byte {
+	var n lineEndingNormalizer
+	return n.normalize(data, true)
+}
+
+// takeLineEnding removes the line ending sentinels from s and remembers the
+// ending they mark for endLine.
+func (n *lineNumberer) takeLineEnding(s string) string {
+	if !n.endings {
+		return s
+	}
+	for _, sentinel := range []rune{crlfSentinel, crSentinel} {
+		text := string(sentinel)
+		if i := strings.Index(s, text); i >= 0 {
+			n.ending = sentinel
+			s = s[:i] + s[i+len(text):]
+		}
+	}
+	return s
+}
+
+// lineEndingGlyph draws the ending of a line: ␍␊ for CRLF, ␍ for CR and ␊
+// for LF.
+func (n *lineNumberer) lineEndingGlyph() string {
+	text := visibleGlyph('\n')
+	switch n.ending {
+	case crlfSentinel:
+		text = visibleGlyph('\r') + text
+	case crSentinel:
+		text = visibleGlyph('\r')
+	}
+	return n.glyph(text)
+}
@@ -0,0 +1,161 @@
+package show
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLineEndingCounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		pieces     []string
+		total      int
+		convention string
+		describe   string
+	}{
+		{"empty", nil, 0, LineEndingsNone, LineEndingsNone},
+		{"no ending", []string{"abc"}, 0, LineEndingsNone, LineEndingsNone},
+		{"lf", []string{"a\nb\n"}, 2, LineEndingsLF, LineEndingsLF},
+		{"crlf", []string{"a\r\nb\r\n"}, 2, LineEndingsCRLF, LineEndingsCRLF},
+		{"cr", []string{"a\rb\r"}, 2, LineEndingsCR, LineEndingsCR},
+		{"crlf split", []string{"a\r", "\nb\r", "\n"}, 2, LineEndingsCRLF, LineEndingsCRLF},
+		{"cr split", []string{"a\r", "b"}, 1, LineEndingsCR, LineEndingsCR},
+		{"mixed", []string{"a\r\nb\rc\nd"}, 3, LineEndingsMixed, "mixed (lf 1, crlf 1, cr 1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var counts lineEndingCounts
+			for _, piece := range tt.pieces {
+				counts.add([]byte(piece))
+			}
+			if got := counts.total(); got != tt.total {
+				t.Fatalf("expected %d endings, got %d", tt.total, got)
+			}
+			if got := counts.convention(); got != tt.convention {
+				t.Fatalf("expected convention %q, got %q", tt.convention, got)
+			}
+			if got := counts.describe(); got != tt.describe {
+				t.Fatalf("expected description %q, got %q", tt.describe, got)
+			}
+		})
+	}
+}
+
+func TestLineEndingNormalizer(t *testing.T) {
+	tests := []struct {
+		name   string
+		mark   bool
+		chunks []string
+		want   string
+	}{
+		{"lf unchanged", false, []string{"a\nb\n"}, "a\nb\n"},
+		{"crlf", false, []string{"a\r\nb\r\n"}, "a\nb\n"},
+		{"cr", false, []string{"a\rb\r"}, "a\nb\n"},
+		{"crlf across chunks", false, []string{"a\r", "\nb"}, "a\nb"},
+		{"cr across chunks", false, []string{"a\r", "b"}, "a\nb"},
+		{"marked", true, []string{"a\r", "\nb\r", "c\n"}, "a\ufdd0\nb\ufdd2\nc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := lineEndingNormalizer{mark: tt.mark}
+			var got []byte
+			for i, chunk := range tt.chunks {
+				got = append(got, n.normalize([]byte(chunk), i == len(tt.chunks)-1)...)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCountLinesInLineEndings(t *testing.T) {
+	for _, input := range []string{
+		strings.Repeat("x\n", 10),
+		strings.Repeat("x\r\n", 10),
+		strings.Repeat("x\r", 10),
+	} {
+		if got := countLinesIn([]byte(input)); got != 10 {
+			t.Fatalf("expected 10 lines in %q, got %d", input, got)
+		}
+	}
+}
+
+func TestRunShowLineEndings(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 4)
+
+	deps := Deps{FileReader: streamReader{data: "one\rtwo\rthree\r"}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: StdinPath, Color: ColorNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	sep := lineSeparator()
+	want := "1 " + sep + " one\n2 " + sep + " two\n3 " + sep + " three\n"
+	if string(result.Content) != want {
+		t.Fatalf("expected CR endings to split lines\nwant %q\ngot  %q", want, result.Content)
+	}
+
+	deps = Deps{FileReader: stubReader{data: []byte("a\r\nb\rc\nd")}}
+	opts := ShowOptions{Path: "a.txt", ShowLineEndings: true, Color: ColorNever, Decorations: DecorationsNever}
+	result, err = RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "a^M$\nb^M\nc$\nd"; string(result.Content) != want {
+		t.Fatalf("expected endings to be marked, got %q", result.Content)
+	}
+}
+
+func TestRunShowLineEndingsCRAtChunkEnd(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 16)
+
+	// The input fills exactly one chunk, so its final CR is held back until
+	// the next read finds the end of the input.
+	data := "one\rtwo\rthree!\r\r"
+	if len(data) != 16 {
+		t.Fatalf("expected a 16 byte input, got %d", len(data))
+	}
+	opts := ShowOptions{Path: StdinPath, Color: ColorNever}
+	result, err := RunShow(t.Context(), Deps{FileReader: streamReader{data: data}}, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var want strings.Builder
+	for i, line := range []string{"one", "two", "three!", ""} {
+		fmt.Fprintf(&want, "%*d %s %s\n", streamLineNumberWidth, i+1, lineSeparator(), line)
+	}
+	if string(result.Content) != want.String() {
+		t.Fatalf("expected the final CR line, got %q", result.Content)
+	}
+}
+
+func TestRunShowDebugLineEndings(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: stubReader{data: []byte("a\r\nb\rc\nd")}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Debug: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "DEBUG line endings: mixed (lf 1, crlf 1, cr 1)"; !strings.Contains(string(result.Content), want) {
+		t.Fatalf("expected %q in output, got %q", want, result.Content)
+	}
+
+	var stderr bytes.Buffer
+	deps.Stderr = &stderr
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Debug: true, DebugFormat: DebugJSON}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var record DebugRecord
+	if err := json.Unmarshal(stderr.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", stderr.String(), err)
+	}
+	if record.Lines != 4 || record.LineEndings != LineEndingsMixed {
+		t.Fatalf("unexpected lines or line endings: %+v", record)
+	}
+}
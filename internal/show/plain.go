@@ -0,0 +1,73 @@
+package show
+
+import (
+	"fmt"
+	"io"
+)
+
+// Decoration modes accepted by ShowOptions.Decorations. Decorations are the
+// line-number gutter and file headers. The zero value always shows them.
+const (
+	DecorationsAuto   = "auto"
+	DecorationsAlways = "always"
+	DecorationsNever  = "never"
+)
+
+func validateDecorations(mode string) error {
+	switch mode {
+	case "", DecorationsAuto, DecorationsAlways, DecorationsNever:
+		return nil
+	default:
+		return fmt.Errorf("unknown decorations mode: %s (want auto, always or never)", mode)
+	}
+}
+
+// Decorate reports whether the gutter and headers are shown. In
+// DecorationsAuto they are only shown on an interactive terminal.
+func Decorate(mode string, terminal bool) bool {
+	switch mode {
+	case DecorationsNever:
+		return false
+	case DecorationsAuto:
+		return terminal
+	default:
+		return true
+	}
+}
+
+// passthrough reports whether rendering would leave the input unchanged,
+// in which case the bytes are copied through without tokenising them.
+func passthrough(opts ShowOptions, color bool) bool {
+	return !color &&
+		opts.Gutter.Hide &&
+		!opts.Header &&
+		!opts.Debug &&
+		len(opts.LineRanges) == 0 &&
+		opts.Output != OutputHTML &&
+		!opts.ShowAll &&
+		!opts.ShowLineEndings &&
+		!wrapping(opts)
+}
+
+// wrapping reports whether opts wrap long lines.
+func wrapping(opts ShowOptions) bool {
+	mode, _ := wrapMode(opts.Wrap, opts.TerminalWidth, opts.Terminal)
+	return mode != WrapNever
+}
+
+// copyChunks writes first and every remaining chunk to w unchanged.
+func copyChunks(w io.Writer, chunks *chunkReader, first []byte) error {
+	for chunk := first; len(chunk) > 0; {
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		if chunks.Done() {
+			return nil
+		}
+		var err error
+		if chunk, err = chunks.Next(); err != nil {
+			return stageError("read file", err)
+		}
+	}
+	return nil
+}
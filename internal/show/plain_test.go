@@ -0,0 +1,57 @@
+package show
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecorate(t *testing.T) {
+	cases := []struct {
+		mode     string
+		terminal bool
+		want     bool
+	}{
+		{"", false, true},
+		{DecorationsAlways, false, true},
+		{DecorationsNever, true, false},
+		{DecorationsAuto, true, true},
+		{DecorationsAuto, false, false},
+	}
+	for _, tc := range cases {
+		if got := Decorate(tc.mode, tc.terminal); got != tc.want {
+			t.Fatalf("Decorate(%q, %v): expected %v", tc.mode, tc.terminal, tc.want)
+		}
+	}
+}
+
+func TestRunShowPassthrough(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	setChunkSize(t, 8)
+
+	input := "no trailing newline\n\tkeeps bytes\x00 as is"
+	var buf bytes.Buffer
+	opts := ShowOptions{Path: "a.go", Color: ColorAuto, Decorations: DecorationsAuto, Header: true}
+	if err := RunShowTo(t.Context(), Deps{FileReader: streamReader{data: input}}, opts, &buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if buf.String() != input {
+		t.Fatalf("expected input copied unchanged, got %q", buf.String())
+	}
+}
+
+func TestRunShowDecorationsNeverWithColor(t *testing.T) {
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n")}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Color: ColorAlways, Decorations: DecorationsNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := string(result.Content)
+	if !strings.Contains(got, "\x1b[") || strings.Contains(stripEscapes(got), "1 ") {
+		t.Fatalf("expected highlighted content without a gutter, got %q", got)
+	}
+
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "main.go", Decorations: "maybe"}); err == nil || !strings.Contains(err.Error(), "unknown decorations mode") {
+		t.Fatalf("expected unknown decorations mode error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package show
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LineRange selects lines Start through End, inclusive and 1-based. A zero
+// Start or End leaves that side of the range open.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ParseLineRange parses "N", "START:END", ":END" and "START:".
+func ParseLineRange(value string) (LineRange, error) {
+	value = strings.TrimSpace(value)
+	startText, endText, isRange := strings.Cut(value, ":")
+	if !isRange {
+		endText = startText
+	}
+
+	var r LineRange
+	var err error
+	if r.Start, err = parseLineNumber(startText); err != nil {
+		return LineRange{}, fmt.Errorf("invalid line range %q: %w", value, err)
+	}
+	if r.End, err = parseLineNumber(endText); err != nil {
+		return LineRange{}, fmt.Errorf("invalid line range %q: %w", value, err)
+	}
+	if !isRange && r.Start == 0 {
+		return LineRange{}, fmt.Errorf("invalid line range %q", value)
+	}
+	if r.End != 0 && r.Start > r.End {
+		return LineRange{}, fmt.Errorf("invalid line range %q: start is after end", value)
+	}
+	return r, nil
+}
+
+func parseLineNumber(text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("line numbers must be positive integers")
+	}
+	return n, nil
+}
+
+func (r LineRange) Contains(line int) bool {
+	return line >= r.Start && (r.End == 0 || line <= r.End)
+}
+
+// lineSelected reports whether line is in any of ranges. No ranges selects
+// every line.
+func lineSelected(ranges []LineRange, line int) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastSelectedLine returns the highest line any of ranges can select, or 0
+// when every line may be selected.
+func lastSelectedLine(ranges []LineRange) int {
+	last := 0
+	for _, r := range ranges {
+		if r.End == 0 {
+			return 0
+		}
+		last = max(last, r.End)
+	}
+	return last
+}
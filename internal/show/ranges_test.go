@@ -0,0 +1,94 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLineRange(t *testing.T) {
+	cases := map[string]LineRange{
+		"120:180": {Start: 120, End: 180},
+		":40":     {End: 40},
+		"500:":    {Start: 500},
+		"42":      {Start: 42, End: 42},
+		" 7:9 ":   {Start: 7, End: 9},
+	}
+	for input, want := range cases {
+		got, err := ParseLineRange(input)
+		if err != nil {
+			t.Fatalf("ParseLineRange(%q): unexpected error %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseLineRange(%q): expected %+v, got %+v", input, want, got)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "0:5", "9:3", "-1", "1:x"} {
+		if _, err := ParseLineRange(input); err == nil || !strings.Contains(err.Error(), "invalid line range") {
+			t.Fatalf("ParseLineRange(%q): expected invalid line range error, got %v", input, err)
+		}
+	}
+}
+
+func TestLineSelected(t *testing.T) {
+	ranges := []LineRange{{End: 2}, {Start: 5, End: 6}, {Start: 9}}
+	for line, want := range map[int]bool{1: true, 2: true, 3: false, 5: true, 7: false, 9: true, 100: true} {
+		if got := lineSelected(ranges, line); got != want {
+			t.Fatalf("lineSelected(%d): expected %v, got %v", line, want, got)
+		}
+	}
+	if !lineSelected(nil, 3) {
+		t.Fatal("expected no ranges to select every line")
+	}
+	if got := lastSelectedLine([]LineRange{{Start: 1, End: 4}, {Start: 8, End: 10}}); got != 10 {
+		t.Fatalf("expected last selected line 10, got %d", got)
+	}
+	if got := lastSelectedLine(ranges); got != 0 {
+		t.Fatalf("expected open range to have no last line, got %d", got)
+	}
+}
+
+func TestRunShowLineRanges(t *testing.T) {
+	setPlainEnv(t)
+
+	var input strings.Builder
+	for i := 1; i <= 12; i++ {
+		input.WriteString("line\n")
+	}
+	opts := ShowOptions{Path: "a.txt", LineRanges: []LineRange{{End: 2}, {Start: 10, End: 11}}}
+	result, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte(input.String())}}, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := " 1 | line\n 2 | line\n10 | line\n11 | line\n"
+	if got := stripEscapes(string(result.Content)); got != want {
+		t.Fatalf("expected selected lines with original numbers\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestRunShowLineRangeInsideBlockComment(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: stubReader{data: []byte("package a\n/* one\ntwo\nthree */\n")}}
+	opts := ShowOptions{Path: "a.go", Color: ColorAlways, ColorDepth: Depth256}
+	full, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	before, _, _ := strings.Cut(string(full.Content), "/* one")
+	comment := before[strings.LastIndex(before, "\x1b["):]
+
+	for _, ranges := range [][]LineRange{nil, {{Start: 3, End: 3}}} {
+		opts.LineRanges = ranges
+		result, err := RunShow(t.Context(), deps, opts)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		_, line, _ := strings.Cut(string(result.Content), "3 |")
+		line, _, _ = strings.Cut(line, "\n")
+		if !strings.Contains(line, comment+"two") {
+			t.Fatalf("ranges %v: expected line 3 in the comment colour %q, got %q", ranges, comment, line)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package show
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RevisionReader is implemented by FileReaders that can serve a file as it
+// was at a git revision.
+type RevisionReader interface {
+	// Revision splits a path argument into the revision it names and the
+	// file path. rev is empty for files read from the work tree.
+	Revision(path string) (rev, file string)
+}
+
+// GitFileReader serves "REV:path" specs, such as HEAD~3:cmd/show/main.go,
+// from git and every other path from Files. A path that exists on disk is
+// always read from Files, so file names containing a colon keep working.
+// As with git show, the path after the colon is relative to the top of the
+// work tree unless it starts with ./ or ../, which make it relative to the
+// current directory. Absolute paths are also accepted. The current
+// directory must be inside the repository.
+type GitFileReader struct {
+	Files FileReader
+}
+
+func (r GitFileReader) Revision(path string) (string, string) {
+	if path == StdinPath {
+		return "", path
+	}
+	rev, file, ok := strings.Cut(path, ":")
+	if !ok || rev == "" || file == "" {
+		return "", path
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return "", path
+	}
+	return rev, file
+}
+
+func (r GitFileReader) ReadFile(path string) ([]byte, error) {
+	rev, file := r.Revision(path)
+	if rev == "" {
+		if r.Files == nil {
+			return nil, errors.New("file reader is required")
+		}
+		return r.Files.ReadFile(path)
+	}
+	src, err := openRevision(context.Background(), rev, file)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return io.ReadAll(src)
+}
+
+func (r GitFileReader) Open(path string) (io.ReadCloser, error) {
+	return r.OpenContext(context.Background(), path)
+}
+
+// OpenContext streams a revision from git, which is stopped when ctx is
+// done.
+func (r GitFileReader) OpenContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	rev, file := r.Revision(path)
+	if rev == "" {
+		if r.Files == nil {
+			return nil, errors.New("file reader is required")
+		}
+		return openFile(ctx, r.Files, path)
+	}
+	return openRevision(ctx, rev, file)
+}
+
+// openRevision streams the contents of file at rev with git cat-file, so no
+// filters or text conversions are applied.
+func openRevision(ctx context.Context, rev, file string) (io.ReadCloser, error) {
+	if filepath.IsAbs(file) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if file, err = filepath.Rel(wd, file); err != nil {
+			return nil, err
+		}
+		file = currentDirPath(file)
+	}
+	return startGit(ctx, ".", "cat-file", "blob", rev+":"+filepath.ToSlash(file))
+}
+
+// currentDirPath prefixes a relative path with ./ unless it already starts
+// with ./ or ../, so that git reads it after "REV:" relative to the current
+// directory rather than the top of the work tree.
+func currentDirPath(path string) string {
+	path = filepath.ToSlash(path)
+	if filepath.IsAbs(path) || path == "." || path == ".." ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		return path
+	}
+	return "./" + path
+}
+
+// revisionWorkTreePath returns where file, the path part of a "REV:path"
+// spec, is in the work tree, resolving a path relative to the top of the
+// work tree with git.
+func revisionWorkTreePath(ctx context.Context, file string) string {
+	if currentDirPath(file) == filepath.ToSlash(file) {
+		return resolvePath(file)
+	}
+	top, err := runGit(ctx, ".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return resolvePath(file)
+	}
+	return resolvePath(filepath.Join(strings.TrimSpace(string(top)), file))
+}
+
+// revisionPath splits path with reader's RevisionReader, if it has one.
+func revisionPath(reader FileReader, path string) (rev, file string) {
+	if revisions, ok := reader.(RevisionReader); ok {
+		return revisions.Revision(path)
+	}
+	return "", path
+}
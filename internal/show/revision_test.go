@@ -0,0 +1,170 @@
+package show
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitFileReaderRevision(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "a:b.txt", "colon\n")
+
+	tests := []struct {
+		path string
+		rev  string
+		file string
+	}{
+		{"HEAD~3:cmd/show/main.go", "HEAD~3", "cmd/show/main.go"},
+		{"main:a.go", "main", "a.go"},
+		{"a.go", "", "a.go"},
+		{StdinPath, "", StdinPath},
+		{":a.go", "", ":a.go"},
+		{"HEAD:", "", "HEAD:"},
+		{"a:b.txt", "", "a:b.txt"},
+	}
+	var reader GitFileReader
+	for _, tt := range tests {
+		rev, file := reader.Revision(tt.path)
+		if rev != tt.rev || file != tt.file {
+			t.Fatalf("%s: expected %q, %q, got %q, %q", tt.path, tt.rev, tt.file, rev, file)
+		}
+	}
+
+	data, err := GitFileReader{Files: OSFileReader{}}.ReadFile("a:b.txt")
+	if err != nil || string(data) != "colon\n" {
+		t.Fatalf("expected the file with a colon to be read, got %q, %v", data, err)
+	}
+	stdin := StdinFileReader{Files: reader}
+	if rev, file := stdin.Revision("HEAD:x.go"); rev != "HEAD" || file != "x.go" {
+		t.Fatalf("expected StdinFileReader to delegate, got %q, %q", rev, file)
+	}
+}
+
+func TestRunShowRevision(t *testing.T) {
+	setPlainEnv(t)
+	dir := initGitRepo(t, map[string]string{
+		"Makefile":        "all:\n\tgo build\n",
+		"cmd/show/run.go": "package main\n",
+	})
+	writeFile(t, filepath.Join(dir, "Makefile"), "changed\n")
+	t.Chdir(filepath.Join(dir, "cmd"))
+
+	deps := Deps{FileReader: StdinFileReader{Files: GitFileReader{Files: OSFileReader{}}}, Git: ExecGit{}}
+	opts := ShowOptions{Path: "HEAD:../Makefile", Debug: true, Color: ColorNever, Tabs: 4}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := string(result.Content)
+	if !strings.Contains(got, "DEBUG file type: Makefile (path") {
+		t.Fatalf("expected the lexer detected from the path part, got %q", got)
+	}
+	if !strings.Contains(got, "go build") || strings.Contains(got, "changed") || strings.Contains(got, " ~ ") {
+		t.Fatalf("expected the committed content without diff markers, got %q", got)
+	}
+
+	opts = ShowOptions{Path: filepath.Join(dir, "cmd/show/run.go"), Rev: "HEAD", Color: ColorNever, Decorations: DecorationsNever}
+	result, err = RunShow(t.Context(), deps, opts)
+	if err != nil || string(result.Content) != "package main\n" {
+		t.Fatalf("expected file read at HEAD by absolute path, got %q, %v", result.Content, err)
+	}
+
+	opts.Path = "show/run.go"
+	result, err = RunShow(t.Context(), deps, opts)
+	if err != nil || string(result.Content) != "package main\n" {
+		t.Fatalf("expected --rev paths relative to the current directory, got %q, %v", result.Content, err)
+	}
+
+	opts.Path = "HEAD:cmd/show/run.go"
+	opts.Rev = ""
+	result, err = RunShow(t.Context(), deps, opts)
+	if err != nil || string(result.Content) != "package main\n" {
+		t.Fatalf("expected REV:path relative to the top of the work tree, got %q, %v", result.Content, err)
+	}
+	opts.Path = "HEAD:show/run.go"
+	if _, err := RunShow(t.Context(), deps, opts); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected REV:path not to be read from the current directory, got %v", err)
+	}
+
+	opts.Rev = "HEAD"
+	opts.Path = "show/missing.go"
+	if _, err := RunShow(t.Context(), deps, opts); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing path error, got %v", err)
+	}
+	opts.Path = StdinPath
+	if _, err := RunShow(t.Context(), deps, opts); err == nil {
+		t.Fatal("expected error for a revision of stdin")
+	}
+	opts = ShowOptions{Path: "HEAD:show/run.go", Diff: true}
+	if _, err := RunShow(t.Context(), deps, opts); err == nil {
+		t.Fatal("expected error for --diff with a revision")
+	}
+}
+
+func TestRunShowRevisionUnsupported(t *testing.T) {
+	setPlainEnv(t)
+
+	opts := ShowOptions{Path: "a.txt", Rev: "HEAD"}
+	_, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte("one\n")}}, opts)
+	if err == nil || !strings.Contains(err.Error(), "cannot read git revisions") {
+		t.Fatalf("expected unsupported revision error, got %v", err)
+	}
+
+	deps := Deps{FileReader: StdinFileReader{Files: OSFileReader{}}}
+	_, err = RunShow(t.Context(), deps, opts)
+	if err == nil || !strings.Contains(err.Error(), "cannot read a.txt at revision HEAD") {
+		t.Fatalf("expected revision split error, got %v", err)
+	}
+}
+
+func TestGitFileReaderOpenContext(t *testing.T) {
+	dir := initGitRepo(t, map[string]string{"a.txt": strings.Repeat("line\n", 100000)})
+	t.Chdir(dir)
+
+	var reader GitFileReader
+	src, err := reader.OpenContext(t.Context(), "HEAD:a.txt")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, ok := src.(io.Seeker); ok {
+		t.Fatal("expected the revision to be streamed rather than loaded")
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(src, buf); err != nil || string(buf) != "line\n" {
+		t.Fatalf("expected the start of the file, got %q, %v", buf, err)
+	}
+	if err := src.Close(); err != nil {
+		t.Fatalf("expected closing early to succeed, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if src, err := reader.OpenContext(ctx, "HEAD:a.txt"); err == nil {
+		_, err = io.ReadAll(src)
+		src.Close()
+		if err == nil {
+			t.Fatal("expected error for a canceled context")
+		}
+	}
+
+	var stderr bytes.Buffer
+	deps := Deps{FileReader: reader, Stderr: &stderr}
+	writeFile(t, filepath.Join(dir, "sub", "b.txt"), "b\n")
+	t.Chdir(filepath.Join(dir, "sub"))
+	opts := ShowOptions{Path: "HEAD:a.txt", Debug: true, DebugFormat: DebugJSON, Decorations: DecorationsNever}
+	if _, err := RunShow(t.Context(), deps, opts); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var record DebugRecord
+	if err := json.Unmarshal(stderr.Bytes(), &record); err != nil {
+		t.Fatalf("expected JSON record, got %q: %v", stderr.String(), err)
+	}
+	if want := resolvePath(filepath.Join(dir, "a.txt")); record.ResolvedPath != want {
+		t.Fatalf("expected resolved path %q, got %q", want, record.ResolvedPath)
+	}
+}
@@ -1,18 +1,23 @@
 package show
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 )
 
 type Deps struct {
 	FileReader FileReader
+	// Stderr receives diagnostics that must not mix with the rendered
+	// output, such as JSON debug records.
+	Stderr io.Writer
+	// Git supplies the diff markers drawn in the gutter. When nil, files
+	// are shown without them.
+	Git Git
 }
 
 type FileReader interface {
@@ -25,112 +30,194 @@ func (OSFileReader) ReadFile(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
-type ShowOptions struct {
-	Path     string
-	FileType string
-	Theme    string
-	Debug    bool
+func (OSFileReader) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
 }
 
-type ShowResult struct {
-	Content []byte
+// StdinPath is the path argument that selects standard input.
+const StdinPath = "-"
+
+// StdinFileReader serves StdinPath from Stdin and delegates every other
+// path to Files.
+type StdinFileReader struct {
+	Stdin io.Reader
+	Files FileReader
 }
 
-func RunShow(ctx context.Context, deps Deps, opts ShowOptions) (ShowResult, error) {
-	if opts.Path == "" {
-		return ShowResult{}, errors.New("path is required")
+func (r StdinFileReader) ReadFile(path string) ([]byte, error) {
+	if path == StdinPath {
+		if r.Stdin == nil {
+			return nil, errors.New("stdin is not available")
+		}
+		return io.ReadAll(r.Stdin)
 	}
-	if deps.FileReader == nil {
-		return ShowResult{}, errors.New("file reader is required")
+	if r.Files == nil {
+		return nil, errors.New("file reader is required")
+	}
+	return r.Files.ReadFile(path)
+}
+
+func (r StdinFileReader) Open(path string) (io.ReadCloser, error) {
+	return r.OpenContext(context.Background(), path)
+}
+
+// OpenContext opens paths other than StdinPath with Files, passing ctx on.
+func (r StdinFileReader) OpenContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	if path == StdinPath {
+		if r.Stdin == nil {
+			return nil, errors.New("stdin is not available")
+		}
+		return io.NopCloser(r.Stdin), nil
 	}
-	if opts.Theme != "" && !IsSupportedTheme(opts.Theme) {
-		return ShowResult{}, fmt.Errorf("unknown theme: %s", opts.Theme)
+	if r.Files == nil {
+		return nil, errors.New("file reader is required")
 	}
+	return openFile(ctx, r.Files, path)
+}
 
-	data, err := deps.FileReader.ReadFile(opts.Path)
-	if err != nil {
-		return ShowResult{}, fmt.Errorf("read file: %w", err)
+// Revision delegates to Files when it is a RevisionReader.
+func (r StdinFileReader) Revision(path string) (string, string) {
+	if path == StdinPath {
+		return "", path
 	}
+	return revisionPath(r.Files, path)
+}
 
-	content := string(data)
-	highlighted, err := highlightContent(opts.Path, content, opts.FileType, opts.Theme)
+// Piped reports whether Stdin carries data from a pipe or redirected file
+// rather than an interactive terminal.
+func (r StdinFileReader) Piped() bool {
+	if r.Stdin == nil {
+		return false
+	}
+	f, ok := r.Stdin.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := f.Stat()
 	if err != nil {
-		return ShowResult{}, fmt.Errorf("highlight content: %w", err)
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
 
-	content = addLineNumbers(highlighted)
-	if opts.Debug {
-		content = wrapWithDebugFileType(opts.Path, data, content)
-	}
-	_ = ctx
-	return ShowResult{Content: []byte(content)}, nil
+type ShowOptions struct {
+	Path     string
+	FileType string
+	// SyntaxMappings pick the file type from the path when FileType is
+	// empty. They are tried in order before Chroma's own filename patterns.
+	SyntaxMappings []SyntaxMapping
+	Theme          string
+	Debug          bool
+	// DebugFormat is DebugText (the default) or DebugJSON.
+	DebugFormat string
+	// Header prefixes the output with the path, detected type and size.
+	Header bool
+	// LineRanges limits output to the selected lines, which keep their
+	// original numbers. The whole file is still highlighted.
+	LineRanges []LineRange
+	// HighlightLines marks lines with a gutter marker and the theme's line
+	// highlight background.
+	HighlightLines []LineRange
+	Gutter         GutterOptions
+	// Output selects OutputTerminal (the default) or OutputHTML.
+	Output string
+	HTML   HTMLOptions
+	// Color is ColorAuto, ColorAlways or ColorNever. When empty, output is
+	// coloured unless NO_COLOR is set.
+	Color string
+	// ColorDepth is Depth16, Depth256 or DepthTrueColor. When empty it is
+	// detected from COLORTERM and TERM.
+	ColorDepth string
+	// Decorations is DecorationsAuto, DecorationsAlways or
+	// DecorationsNever. When empty the gutter and headers are shown.
+	Decorations string
+	// Terminal reports whether the output is an interactive terminal, which
+	// ColorAuto, DecorationsAuto and WrapAuto require.
+	Terminal bool
+	// Wrap is WrapAuto, WrapNever, WrapCharacter or WrapWord. When empty it
+	// behaves like WrapAuto.
+	Wrap string
+	// TerminalWidth is the number of columns lines wrap at, including the
+	// gutter. Zero means unknown: WrapAuto does not wrap and the other
+	// modes assume 80 columns.
+	TerminalWidth int
+	// Tabs expands tabs to spaces at multiples of this many columns from
+	// the start of the content column. Zero passes tabs through, as does
+	// output that is copied through undecorated.
+	Tabs int
+	// ShowAll draws tabs, trailing spaces, line endings and invisible or
+	// control characters as coloured glyphs. It applies to terminal
+	// output only.
+	ShowAll bool
+	// Hex renders the input as a hexdump of offsets, bytes and printable
+	// ASCII. Without it, binary input is refused with ErrBinary unless it
+	// is copied through undecorated to something other than a terminal.
+	Hex bool
+	// Encoding names the input's character encoding (see Encodings). When
+	// empty or EncodingAuto it is detected from a byte order mark or the
+	// content. Input that is not UTF-8 is transcoded before highlighting.
+	Encoding string
+	// ShowLineEndings marks the end of each line with ␊ for LF, ␍␊ for
+	// CRLF or ␍ for CR. CRLF and CR endings are shown as line breaks
+	// either way. ShowAll implies it.
+	ShowLineEndings bool
+	// DiffBase is the revision the gutter's git diff markers compare the
+	// file against. When empty the index is used, or HEAD with Diff.
+	DiffBase string
+	// NoGit drops the git diff markers even when Deps.Git is set.
+	NoGit bool
+	// Diff shows only the lines that changed against DiffBase, or HEAD
+	// when it is empty, with DiffContext lines around each change. Lines
+	// keep their numbers and a divider separates the hunks.
+	Diff        bool
+	DiffContext int
+	// Rev reads Path as it was at this git revision, as if Path were
+	// "Rev:Path". The FileReader must be a RevisionReader such as
+	// GitFileReader.
+	Rev string
 }
 
-func wrapWithDebugFileType(path string, data []byte, content string) string {
-	fileType := detectFileType(path, data)
-	line := fmt.Sprintf("DEBUG file type: %s", fileType)
+// FileOpener is implemented by FileReaders that can stream a path instead
+// of loading it into memory. RunShowTo prefers it when available.
+type FileOpener interface {
+	Open(path string) (io.ReadCloser, error)
+}
 
-	var b strings.Builder
-	b.WriteString(line)
-	b.WriteByte('\n')
-	b.WriteString(content)
-	if content != "" && !strings.HasSuffix(content, "\n") {
-		b.WriteByte('\n')
-	}
-	b.WriteByte('\n')
-	b.WriteString(line)
-	b.WriteByte('\n')
-	b.WriteByte('\n')
-	return b.String()
+// ContextOpener is implemented by FileOpeners whose work should stop with
+// the context RunShowTo was given, such as running git for a revision.
+// RunShowTo prefers it over FileOpener.
+type ContextOpener interface {
+	OpenContext(ctx context.Context, path string) (io.ReadCloser, error)
 }
 
-func detectFileType(path string, data []byte) string {
-	return detectFileTypeFromExtension(path)
+type ShowResult struct {
+	Content []byte
 }
 
-func addLineNumbers(input string) string {
-	if input == "" {
-		return ""
+// RunShow renders opts.Path and returns the complete output. It is a
+// buffered wrapper around RunShowTo.
+func RunShow(ctx context.Context, deps Deps, opts ShowOptions) (ShowResult, error) {
+	var buf bytes.Buffer
+	if err := RunShowTo(ctx, deps, opts, &buf); err != nil {
+		return ShowResult{}, err
 	}
+	return ShowResult{Content: buf.Bytes()}, nil
+}
 
-	width := lineNumberWidth(input)
-	var b strings.Builder
-	reader := bufio.NewReader(strings.NewReader(input))
-	lineNum := 1
-	lineStarted := false
-	sep := lineSeparator()
-	reset := "\x1b[0m"
-	white := "\x1b[37m"
-	useColor := !noColor()
-	for {
-		line, err := reader.ReadString('\n')
-		if line != "" {
-			if errors.Is(err, io.EOF) && !strings.HasSuffix(line, "\n") {
-				b.WriteString(line)
-				break
-			}
-			if !lineStarted {
-				if useColor {
-					fmt.Fprintf(&b, "%s%s%*d %s%s ", reset, white, width, lineNum, sep, reset)
-				} else {
-					fmt.Fprintf(&b, "%*d %s ", width, lineNum, sep)
-				}
-				lineStarted = true
-			}
-			b.WriteString(line)
-			if strings.HasSuffix(line, "\n") {
-				lineNum++
-				lineStarted = false
-			}
-		}
-		if errors.Is(err, bufio.ErrBufferFull) {
-			continue
-		}
-		if errors.Is(err, io.EOF) || err != nil {
-			break
-		}
+func debugFileTypeLine(d detection) string {
+	return fmt.Sprintf("DEBUG file type: %s (%s, confidence %.2f)", d.name(), d.rule, d.confidence)
+}
+
+func debugLineEndingsLine(endings *lineEndingCounts) string {
+	return "DEBUG line endings: " + endings.describe()
+}
+
+func debugEncodingLine(name string, forced bool) string {
+	source := "detected"
+	if forced {
+		source = "forced"
 	}
-	return b.String()
+	return fmt.Sprintf("DEBUG encoding: %s (%s)", name, source)
 }
 
 func lineSeparator() string {
@@ -155,16 +242,14 @@ func noColor() bool {
 	return ok && value != ""
 }
 
-func lineNumberWidth(input string) int {
-	if input == "" {
-		return 1
-	}
-	lines := strings.Count(input, "\n")
-	if !strings.HasSuffix(input, "\n") {
-		lines++
-	}
-	if lines == 0 {
-		lines = 1
+// countLinesIn counts lines ending in LF, CRLF or CR, including a final
+// line without an ending. Empty input counts as one line.
+func countLinesIn(data []byte) int {
+	var endings lineEndingCounts
+	endings.add(data)
+	var last byte
+	if len(data) > 0 {
+		last = data[len(data)-1]
 	}
-	return len(strconv.Itoa(lines))
+	return countedLines(&endings, int64(len(data)), last)
 }
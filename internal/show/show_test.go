@@ -4,6 +4,8 @@ import (
 	"errors"
 	"strings"
 	"testing"
+
+	"github.com/alecthomas/chroma/v2"
 )
 
 type stubReader struct {
@@ -15,6 +17,17 @@ func (s stubReader) ReadFile(string) ([]byte, error) {
 	return s.data, s.err
 }
 
+// pathReader serves files by path, for tests that read more than one file.
+type pathReader map[string]string
+
+func (r pathReader) ReadFile(path string) ([]byte, error) {
+	data, ok := r[path]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return []byte(data), nil
+}
+
 func TestRunShowErrors(t *testing.T) {
 	t.Run("missing path", func(t *testing.T) {
 		_, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte("ok")}}, ShowOptions{})
@@ -71,26 +84,25 @@ func TestRunShowDebug(t *testing.T) {
 	}
 }
 
-func TestAddLineNumbers(t *testing.T) {
-	t.Setenv("NO_COLOR", "1")
-	t.Setenv("LC_ALL", "C")
-	t.Setenv("LC_CTYPE", "C")
-	t.Setenv("LANG", "C")
+func TestRunShowLineNumbers(t *testing.T) {
+	setPlainEnv(t)
 
-	out := addLineNumbers("first\nsecond\n")
-	if !strings.Contains(out, "1 | ") || !strings.Contains(out, "2 | ") {
-		t.Fatalf("expected line numbers, got %q", out)
+	deps := Deps{FileReader: stubReader{data: []byte("first\nsecond\n")}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Color: ColorNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "1 | first\n2 | second\n"; string(result.Content) != want {
+		t.Fatalf("expected %q, got %q", want, result.Content)
 	}
 }
 
-func TestLineNumberWidth(t *testing.T) {
-	if got := lineNumberWidth(""); got != 1 {
-		t.Fatalf("expected width 1, got %d", got)
+func TestCountLinesIn(t *testing.T) {
+	if got := countLinesIn(nil); got != 1 {
+		t.Fatalf("expected 1 line, got %d", got)
 	}
-
-	input := strings.Repeat("x\n", 9) + "x"
-	if got := lineNumberWidth(input); got != 2 {
-		t.Fatalf("expected width 2, got %d", got)
+	if got := countLinesIn([]byte(strings.Repeat("x\n", 9) + "x")); got != 10 {
+		t.Fatalf("expected 10 lines, got %d", got)
 	}
 }
 
@@ -129,3 +141,94 @@ func TestNoColor(t *testing.T) {
 		}
 	})
 }
+
+func TestStdinFileReader(t *testing.T) {
+	reader := StdinFileReader{
+		Stdin: strings.NewReader("from stdin\n"),
+		Files: stubReader{data: []byte("from file\n")},
+	}
+
+	data, err := reader.ReadFile(StdinPath)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if string(data) != "from stdin\n" {
+		t.Fatalf("expected stdin content, got %q", data)
+	}
+
+	data, err = reader.ReadFile("file.txt")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if string(data) != "from file\n" {
+		t.Fatalf("expected file content, got %q", data)
+	}
+
+	if !reader.Piped() {
+		t.Fatal("expected non-terminal stdin to be piped")
+	}
+	if (StdinFileReader{}).Piped() {
+		t.Fatal("expected missing stdin not to be piped")
+	}
+}
+
+func TestRunShowStdin(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: StdinFileReader{Stdin: strings.NewReader("package main\n")}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: StdinPath, FileType: "go"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(string(result.Content), "1 | ") {
+		t.Fatalf("expected line numbers, got %q", result.Content)
+	}
+	if !strings.Contains(string(result.Content), "package") {
+		t.Fatalf("expected content, got %q", result.Content)
+	}
+}
+
+func TestRunShowHighlightLines(t *testing.T) {
+	setPlainEnv(t)
+	t.Setenv("NO_COLOR", "")
+
+	opts := ShowOptions{Path: "a.txt", HighlightLines: []LineRange{{Start: 2, End: 2}}}
+	result, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte("one\ntwo\nthree\n")}}, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	lines := strings.Split(string(result.Content), "\n")
+	bg := lineHighlightBackground(selectStyle(""), colorDepth(""))
+	if !strings.Contains(lines[1], "2 >") || !strings.Contains(lines[1], bg) || !strings.HasSuffix(lines[1], "\x1b[K\x1b[0m") {
+		t.Fatalf("expected marker and background on line 2, got %q", lines[1])
+	}
+	for _, i := range []int{0, 2} {
+		if strings.Contains(lines[i], bg) || !strings.Contains(lines[i], " |") {
+			t.Fatalf("expected line %d to be unmarked, got %q", i+1, lines[i])
+		}
+	}
+}
+
+func TestRunShowHighlightLinesNoColor(t *testing.T) {
+	setPlainEnv(t)
+
+	opts := ShowOptions{Path: "a.txt", HighlightLines: []LineRange{{Start: 1, End: 1}}}
+	result, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte("one\ntwo\n")}}, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got := stripEscapes(string(result.Content)); got != "1 > one\n2 | two\n" {
+		t.Fatalf("expected marker only, got %q", got)
+	}
+	if strings.Contains(string(result.Content), "\x1b[K") {
+		t.Fatalf("expected no background with NO_COLOR, got %q", result.Content)
+	}
+}
+
+func TestLineHighlightBackground(t *testing.T) {
+	style := chroma.MustNewStyle("test", chroma.StyleEntries{chroma.LineHighlight: "bg:#102030"})
+	if got := lineHighlightBackground(style, DepthTrueColor); got != "\x1b[48;2;16;32;48m" {
+		t.Fatalf("expected style line highlight colour, got %q", got)
+	}
+}
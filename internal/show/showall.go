@@ -0,0 +1,160 @@
+package show
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// showAllColor colours the glyphs that stand in for invisible characters.
+const showAllColor = "\x1b[90m"
+
+// The line numberer treats ESC as the start of an escape sequence, so with
+// ShowOptions.ShowAll it is swapped for a Unicode noncharacter before
+// tokenising and drawn as a glyph afterwards.
+const escapeSentinel = '\uFDD1'
+
+// protectControls replaces ESC bytes with escapeSentinel.
+func protectControls(chunk []byte) []byte {
+	if bytes.IndexByte(chunk, '\x1b') < 0 {
+		return chunk
+	}
+	return bytes.ReplaceAll(chunk, []byte{'\x1b'}, []byte(string(escapeSentinel)))
+}
+
+// visibleGlyph returns the text drawn for r with ShowAll, or "" when r is
+// printed as is. Spaces are handled by the caller, since only trailing
+// ones are marked.
+func visibleGlyph(r rune) string {
+	utf8Locale := isUTF8Locale()
+	pick := func(unicode string, ascii string) string {
+		if utf8Locale {
+			return unicode
+		}
+		return ascii
+	}
+	switch {
+	case r == '\t':
+		return pick("→", ">")
+	case r == '\n':
+		return pick("␊", "$")
+	case r == '\r':
+		return pick("␍", "^M")
+	case r == escapeSentinel:
+		return pick("␛", "^[")
+	case r == '\u00a0' || r == '\u2007' || r == '\u202f':
+		return pick("⍽", "_")
+	case r < 0x20:
+		return pick(string(rune(0x2400+r)), "^"+string(rune(r+'@')))
+	case r == 0x7f:
+		return pick("␡", "^?")
+	case unicode.Is(unicode.Cc, r), unicode.Is(unicode.Cf, r), r == '\u2028', r == '\u2029':
+		return fmt.Sprintf("<U+%04X>", r)
+	}
+	return ""
+}
+
+// visible draws tabs, trailing spaces and invisible characters in s as
+// coloured glyphs. Spaces are held back until the rest of the line shows
+// whether they are trailing. Tabs keep their alignment: the arrow is padded
+// to the next tab stop.
+func (n *lineNumberer) visible(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			seq := s[i : i+l]
+			i += l
+			n.trackStyle(seq)
+			if n.spaces.Len() > 0 {
+				n.spaces.WriteString(seq)
+			} else {
+				b.WriteString(seq)
+			}
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		glyph := s[i : i+size]
+		i += size
+		if r == ' ' {
+			n.spaces.WriteByte(' ')
+			n.tabCol++
+			continue
+		}
+		n.releaseSpaces(&b, false)
+		text := visibleGlyph(r)
+		if text == "" {
+			b.WriteString(glyph)
+			n.tabCol += runewidth.RuneWidth(r)
+			continue
+		}
+		width := runewidth.StringWidth(text)
+		if r == '\t' {
+			stop := n.tabs
+			if stop == 0 {
+				stop = terminalTabStop
+			}
+			width = max(stop-n.tabCol%stop, width)
+			text += strings.Repeat(" ", width-runewidth.StringWidth(text))
+		}
+		b.WriteString(n.glyph(text))
+		n.tabCol += width
+	}
+	return b.String()
+}
+
+// endVisibleLine marks the spaces held back at the end of a line.
+func (n *lineNumberer) endVisibleLine() string {
+	var b strings.Builder
+	n.releaseSpaces(&b, true)
+	return b.String()
+}
+
+// releaseSpaces writes the held back spaces to b, as glyphs when they are
+// trailing.
+func (n *lineNumberer) releaseSpaces(b *strings.Builder, trailing bool) {
+	if n.spaces.Len() == 0 {
+		return
+	}
+	held := n.spaces.String()
+	n.spaces.Reset()
+	if !trailing {
+		b.WriteString(held)
+		return
+	}
+	dot := "·"
+	if !isUTF8Locale() {
+		dot = "."
+	}
+	for i := 0; i < len(held); {
+		if l := escapeLen(held[i:]); l > 0 {
+			b.WriteString(held[i : i+l])
+			i += l
+			continue
+		}
+		b.WriteString(n.glyph(dot))
+		i++
+	}
+}
+
+// glyph colours text and then restores the colours the formatter had set.
+func (n *lineNumberer) glyph(text string) string {
+	if n.glyphColor == "" {
+		return text
+	}
+	return n.glyphColor + text + "\x1b[0m" + n.style.String()
+}
+
+// trackStyle remembers the formatter's SGR sequences since the last reset,
+// so they can be restored after a glyph.
+func (n *lineNumberer) trackStyle(seq string) {
+	switch {
+	case isReset(seq):
+		n.style.Reset()
+	case strings.HasSuffix(seq, "m"):
+		n.style.WriteString(seq)
+	}
+}
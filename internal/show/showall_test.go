@@ -0,0 +1,60 @@
+package show
+
+import "testing"
+
+func showAll(tabs int, color string) func(*lineNumberer) {
+	return func(n *lineNumberer) {
+		n.tabs = tabs
+		n.showAll = true
+		n.endings = true
+		n.glyphColor = color
+	}
+}
+
+func TestShowAll(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{Hide: true}, showAll(4, ""), "a\tb c  ", " \n\x01\x7f\u00a0\u200b\ufeff")
+	if want := "a>  b c...$\n^A^?_<U+200B><U+FEFF>"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	endings := lineEndingNormalizer{mark: true}
+	got = numberLines(t, GutterOptions{Hide: true}, showAll(4, ""), string(protectControls(endings.normalize([]byte("x\r\n\x1b[1m \r\n"), true))))
+	if want := "x^M$\n^[[1m.^M$\n"; got != want {
+		t.Fatalf("expected carriage returns and escapes to be drawn, got %q", got)
+	}
+}
+
+func TestShowAllUTF8(t *testing.T) {
+	setPlainEnv(t)
+	t.Setenv("LANG", "en_US.UTF-8")
+
+	got := numberLines(t, GutterOptions{Hide: true}, showAll(0, ""), "\tx \n")
+	if want := "→       x·␊\n"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestShowAllRestoresColours(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{Hide: true}, showAll(2, "\x1b[90m"), "\x1b[31ma\tb\x1b[0m\n")
+	want := "\x1b[31ma\x1b[90m>\x1b[0m\x1b[31mb\x1b[0m\x1b[90m$\x1b[0m\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunShowShowAll(t *testing.T) {
+	setPlainEnv(t)
+	deps := Deps{FileReader: stubReader{data: []byte("key: value \r\n")}}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.yaml", ShowAll: true, Color: ColorNever, Decorations: DecorationsNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "key: value.^M$\n"; string(result.Content) != want {
+		t.Fatalf("expected %q, got %q", want, result.Content)
+	}
+}
@@ -0,0 +1,579 @@
+package show
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// streamChunkSize bounds how much input is tokenised at once. Lexer state is
+// not carried across chunks, so constructs spanning a chunk boundary may be
+// coloured as if they started fresh.
+var streamChunkSize = 256 * 1024
+
+// streamLineNumberWidth is the gutter width used when the number of lines
+// cannot be known up front, such as for piped input larger than one chunk.
+const streamLineNumberWidth = 6
+
+// RunShowTo renders opts.Path to w as it is read. Input is tokenised and
+// numbered one chunk at a time, so memory use is bounded by the chunk size
+// rather than the file size.
+func RunShowTo(ctx context.Context, deps Deps, opts ShowOptions, w io.Writer) (err error) {
+	if opts.Path == "" {
+		return errors.New("path is required")
+	}
+	if deps.FileReader == nil {
+		return errors.New("file reader is required")
+	}
+	if opts.Theme != "" && !IsSupportedTheme(opts.Theme) {
+		return fmt.Errorf("unknown theme: %s", opts.Theme)
+	}
+	if err := validateColor(opts.Color, opts.ColorDepth); err != nil {
+		return err
+	}
+	color := useColor(opts.Color, opts.Terminal)
+	depth := colorDepth(opts.ColorDepth)
+	if err := opts.Gutter.validate(depth); err != nil {
+		return err
+	}
+	if err := validateOutput(opts.Output); err != nil {
+		return err
+	}
+	if err := validateDecorations(opts.Decorations); err != nil {
+		return err
+	}
+	if err := validateDebugFormat(opts.DebugFormat); err != nil {
+		return err
+	}
+	if err := validateWrap(opts.Wrap, opts.TerminalWidth); err != nil {
+		return err
+	}
+	if err := validateTabs(opts.Tabs); err != nil {
+		return err
+	}
+	forcedEncoding, err := ParseEncoding(opts.Encoding)
+	if err != nil {
+		return err
+	}
+	if opts.Hex && opts.Output == OutputHTML {
+		return errors.New("hex output cannot be combined with html output")
+	}
+	if err := validateDiff(opts, deps.Git); err != nil {
+		return err
+	}
+	if opts.Diff && opts.DiffBase == "" {
+		opts.DiffBase = "HEAD"
+	}
+	if opts.Rev != "" {
+		if opts.Path == StdinPath {
+			return errors.New("a revision cannot be read from stdin")
+		}
+		if _, ok := deps.FileReader.(RevisionReader); !ok {
+			return errors.New("the file reader cannot read git revisions")
+		}
+		path := opts.Path
+		opts.Path = opts.Rev + ":" + currentDirPath(path)
+		if rev, _ := revisionPath(deps.FileReader, opts.Path); rev != opts.Rev {
+			return fmt.Errorf("cannot read %s at revision %s", path, opts.Rev)
+		}
+	}
+	rev, file := revisionPath(deps.FileReader, opts.Path)
+	if opts.Diff && rev != "" {
+		return errors.New("diff output cannot be combined with a revision")
+	}
+	var record *debugRecorder
+	if opts.Debug && opts.DebugFormat == DebugJSON {
+		if deps.Stderr == nil {
+			return errors.New("stderr is required for json debug output")
+		}
+		record = newDebugRecorder(opts.Path)
+		record.revision(ctx, rev, file)
+		opts.Debug = false
+		defer func() {
+			failure := err
+			if errors.Is(failure, ErrNoChanges) {
+				failure = nil
+			}
+			if writeErr := record.write(deps.Stderr, failure); failure == nil && writeErr != nil {
+				err = writeErr
+			}
+		}()
+	}
+	if !Decorate(opts.Decorations, opts.Terminal) {
+		opts.Header = false
+		opts.Gutter.Hide = true
+	}
+
+	src, err := openFile(ctx, deps.FileReader, opts.Path)
+	if err != nil {
+		return stageError("read file", err)
+	}
+	defer src.Close()
+
+	var changes lineChanges
+	if opts.Diff || (rev == "" && !opts.Gutter.Hide && !opts.Hex && opts.Output != OutputHTML) {
+		if changes, err = gitChanges(ctx, deps.Git, opts); err != nil {
+			return stageError("diff file", err)
+		}
+	}
+	if opts.Diff {
+		if changes == nil {
+			return stageError("diff file", ErrNotRepository)
+		}
+		if len(changes) == 0 {
+			defer func() {
+				if err == nil {
+					err = fmt.Errorf("%w against %s", ErrNoChanges, opts.DiffBase)
+				}
+			}()
+		}
+		opts.LineRanges = changes.ranges(opts.DiffContext)
+	}
+	if opts.Gutter.Hide {
+		changes = nil
+	}
+
+	size, sized := sourceSize(src)
+	sample, input, err := peekSource(src, encodingSampleSize)
+	if err != nil {
+		return stageError("read file", err)
+	}
+	encodingName := forcedEncoding
+	if encodingName == "" {
+		encodingName = detectEncoding(sample)
+	}
+	encodingLine := debugEncodingLine(encodingName, forcedEncoding != "")
+	var enc encoding.Encoding
+	if !opts.Hex {
+		enc = decoder(encodingName, opts.ShowAll)
+	}
+
+	width := 0
+	var tail []byte
+	if seeker, ok := input.(io.ReadSeeker); ok {
+		var lines int
+		lines, tail, err = countLines(ctx, seeker, enc)
+		if err != nil {
+			return stageError("read file", err)
+		}
+		width = opts.Gutter.gutterWidth(lines)
+		record.lines(lines)
+	}
+
+	var text io.Reader = contextReader{ctx: ctx, r: input}
+	if enc != nil {
+		text = transform.NewReader(text, enc.NewDecoder())
+	}
+	chunks := newChunkReader(text, streamChunkSize)
+	first, err := chunks.Next()
+	if err != nil {
+		return stageError("read file", err)
+	}
+	if width == 0 {
+		width = max(streamLineNumberWidth, opts.Gutter.MinWidth)
+		if chunks.Done() {
+			width = opts.Gutter.gutterWidth(countLinesIn(first))
+		}
+	}
+	// A byte order mark kept for ShowAll must not hide a #! line or a
+	// modeline from detection.
+	head := bytes.TrimPrefix(first, []byte(byteOrderMark))
+	if chunks.Done() {
+		tail = head
+	}
+	record.source(size, sized, chunks, encodingName)
+	record.stage("read")
+
+	detected, err := detectLexer(ctx, deps.FileReader, file, head, tail, opts.FileType, opts.SyntaxMappings)
+	if err != nil {
+		return fmt.Errorf("highlight content: %w", err)
+	}
+	lexer := detected.lexer
+	record.detected(detected)
+	record.stage("detect")
+	formatter, err := selectFormatter(depth, color)
+	if err != nil {
+		return fmt.Errorf("highlight content: %w", err)
+	}
+	style := selectStyle(opts.Theme)
+	binary := isBinary(first)
+	if opts.Hex {
+		record.rendering("hexdump", style.Name, color, depth)
+		if opts.Header {
+			name := detected.name()
+			if binary {
+				name = "binary"
+			}
+			if _, err := io.WriteString(w, fileHeader(opts.Path, name, size, sized, color)); err != nil {
+				return err
+			}
+		}
+		return runHex(w, opts, chunks, first, color, depth)
+	}
+	// Binary input is only copied through when it cannot reach a terminal.
+	if binary && (opts.Terminal || !passthrough(opts, color)) {
+		return fmt.Errorf("%w; use --hex to view it as a hexdump", ErrBinary)
+	}
+	if passthrough(opts, color) {
+		record.rendering("passthrough", style.Name, color, depth)
+		return copyChunks(w, chunks, first)
+	}
+	if opts.Output == OutputHTML {
+		record.rendering(OutputHTML, style.Name, color, depth)
+		return runShowHTML(ctx, w, opts, chunks, first, size, sized, detected, encodingLine, style)
+	}
+	record.rendering(formatterName(depth, color), style.Name, color, depth)
+
+	if opts.Header {
+		if _, err := io.WriteString(w, fileHeader(opts.Path, detected.name(), size, sized, color)); err != nil {
+			return err
+		}
+	}
+	debugLine := ""
+	if opts.Debug {
+		debugLine = debugFileTypeLine(detected)
+		if _, err := io.WriteString(w, debugLine+"\n"+encodingLine+"\n"); err != nil {
+			return err
+		}
+	}
+
+	content := &trackingWriter{w: w}
+	numbers := newLineNumberer(content, width)
+	if err := numbers.configure(opts.Gutter, color, depth); err != nil {
+		return err
+	}
+	numbers.changes = changes
+	numbers.divider = opts.Diff
+	numbers.configureWrap(wrapMode(opts.Wrap, opts.TerminalWidth, opts.Terminal))
+	numbers.tabs = opts.Tabs
+	numbers.showAll = opts.ShowAll
+	numbers.endings = opts.ShowAll || opts.ShowLineEndings
+	if color {
+		numbers.glyphColor = showAllColor
+	}
+	numbers.ctx = ctx
+	numbers.ranges = opts.LineRanges
+	numbers.highlights = opts.HighlightLines
+	if color {
+		numbers.highlightBG = lineHighlightBackground(style, depth)
+	}
+	endings := lineEndingNormalizer{mark: numbers.endings}
+	// A CR held back at the end of a chunk still ends a line when the next
+	// read finds nothing more.
+	for chunk := first; len(chunk) > 0 || endings.pendingCR; {
+		text := endings.normalize(chunk, chunks.Done())
+		if opts.ShowAll {
+			text = protectControls(text)
+		}
+		if err := formatChunk(ctx, numbers, formatter, style, lexer, text, chunks.Done()); err != nil {
+			return stageError("highlight content", err)
+		}
+		if numbers.err != nil {
+			return numbers.err
+		}
+		if chunks.Done() || numbers.Done() {
+			break
+		}
+		if chunk, err = chunks.Next(); err != nil {
+			return stageError("read file", err)
+		}
+	}
+	if err := numbers.Flush(); err != nil {
+		return err
+	}
+
+	if opts.Debug {
+		var b strings.Builder
+		if content.n > 0 && content.last != '\n' {
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+		b.WriteString(debugLine)
+		b.WriteByte('\n')
+		b.WriteString(debugLineEndingsLine(&chunks.endings))
+		b.WriteString("\n\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runShowHTML reads the rest of the input and renders it as one HTML
+// snippet, since the HTML formatter numbers lines across the whole file.
+func runShowHTML(ctx context.Context, w io.Writer, opts ShowOptions, chunks *chunkReader, first []byte, size int64, sized bool, detected detection, encodingLine string, style *chroma.Style) error {
+	content := first
+	for !chunks.Done() {
+		chunk, err := chunks.Next()
+		if err != nil {
+			return stageError("read file", err)
+		}
+		content = append(content, chunk...)
+	}
+
+	if opts.Header {
+		if _, err := io.WriteString(w, htmlComment(fileHeaderText(opts.Path, detected.name(), size, sized))); err != nil {
+			return err
+		}
+	}
+	if opts.Debug {
+		if _, err := io.WriteString(w, htmlComment(debugFileTypeLine(detected))+htmlComment(encodingLine)+htmlComment(debugLineEndingsLine(&chunks.endings))); err != nil {
+			return err
+		}
+	}
+	if err := renderHTML(ctx, w, opts, detected.lexer, style, string(normalizeLineEndings(content))); err != nil {
+		return stageError("highlight content", err)
+	}
+	return nil
+}
+
+// stageError prefixes err with the pipeline stage that failed. Cancellation
+// is returned as is, since CanceledError already names its stage.
+func stageError(stage string, err error) error {
+	var cerr *CanceledError
+	if errors.As(err, &cerr) {
+		return cerr
+	}
+	return fmt.Errorf("%s: %w", stage, err)
+}
+
+// formatChunk highlights one chunk into w. Lexers that ensure a trailing
+// newline would split a line that continues in the next chunk, so that
+// newline is dropped unless this is the last chunk.
+func formatChunk(ctx context.Context, w io.Writer, formatter chroma.Formatter, style *chroma.Style, lexer chroma.Lexer, chunk []byte, last bool) error {
+	if err := canceled(ctx, "highlight content"); err != nil {
+		return err
+	}
+	iterator, err := lexer.Tokenise(nil, string(chunk))
+	if err != nil {
+		return err
+	}
+	if !last && !bytes.HasSuffix(chunk, []byte("\n")) {
+		iterator = trimFinalNewline(iterator)
+	}
+	var cancelErr error
+	iterator = contextIterator(ctx, iterator, &cancelErr)
+	if err := formatter.Format(w, style, iterator); err != nil {
+		return err
+	}
+	return cancelErr
+}
+
+func trimFinalNewline(it chroma.Iterator) chroma.Iterator {
+	next := it()
+	return func() chroma.Token {
+		token := next
+		if token == chroma.EOF {
+			return token
+		}
+		next = it()
+		if next == chroma.EOF {
+			token.Value = strings.TrimSuffix(token.Value, "\n")
+		}
+		return token
+	}
+}
+
+func openFile(ctx context.Context, reader FileReader, path string) (io.ReadCloser, error) {
+	if opener, ok := reader.(ContextOpener); ok {
+		return opener.OpenContext(ctx, path)
+	}
+	if opener, ok := reader.(FileOpener); ok {
+		return opener.Open(path)
+	}
+	data, err := readFileContext(ctx, reader, path)
+	if err != nil {
+		return nil, err
+	}
+	return memorySource{bytes.NewReader(data)}, nil
+}
+
+// memorySource serves data already loaded by FileReader.ReadFile while
+// keeping the Seek and Len methods that a plain io.NopCloser would hide.
+type memorySource struct {
+	*bytes.Reader
+}
+
+func (memorySource) Close() error {
+	return nil
+}
+
+// sourceSize reports the size of r when it can be known without reading it.
+func sourceSize(r io.Reader) (int64, bool) {
+	switch src := r.(type) {
+	case *os.File:
+		info, err := src.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		return info.Size(), true
+	case interface{ Len() int }:
+		return int64(src.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+// countLines counts lines the same way countLinesIn does and rewinds r. It
+// also returns the last modelineTailSize bytes, for modelines at the end of
+// the file. When enc is not nil, r is decoded with it first.
+func countLines(ctx context.Context, r io.ReadSeeker, enc encoding.Encoding) (int, []byte, error) {
+	var src io.Reader = r
+	if enc != nil {
+		src = transform.NewReader(r, enc.NewDecoder())
+	}
+	buf := make([]byte, 32*1024)
+	var endings lineEndingCounts
+	var read int64
+	var last byte
+	var tail []byte
+	for {
+		if err := canceled(ctx, "read file"); err != nil {
+			return 0, nil, err
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			endings.add(buf[:n])
+			read += int64(n)
+			last = buf[n-1]
+			tail = append(tail, buf[:n]...)
+			if len(tail) > 2*modelineTailSize {
+				tail = append(tail[:0], tail[len(tail)-modelineTailSize:]...)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	lines := countedLines(&endings, read, last)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+	return lines, tail[max(len(tail)-modelineTailSize, 0):], nil
+}
+
+// chunkReader splits input into chunks of roughly size bytes. Chunks end on
+// a line boundary unless a single line is longer than size, and never split
+// a UTF-8 sequence.
+type chunkReader struct {
+	r     *bufio.Reader
+	size  int
+	carry []byte
+	done  bool
+
+	// endings, read and last describe the chunks returned so far.
+	endings lineEndingCounts
+	read    int64
+	last    byte
+}
+
+func newChunkReader(r io.Reader, size int) *chunkReader {
+	return &chunkReader{r: bufio.NewReaderSize(r, size), size: size}
+}
+
+// Next returns the next chunk. An empty chunk with a nil error means the
+// input is exhausted.
+func (c *chunkReader) Next() ([]byte, error) {
+	chunk := c.carry
+	c.carry = nil
+	for len(chunk) < c.size && !c.done {
+		line, err := c.r.ReadSlice('\n')
+		chunk = append(chunk, line...)
+		if errors.Is(err, io.EOF) {
+			c.done = true
+			break
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+	}
+	if !c.done && !bytes.HasSuffix(chunk, []byte("\n")) {
+		// Prefer to end the chunk after a CR line ending.
+		cut := bytes.LastIndexByte(chunk, '\r') + 1
+		if cut == 0 {
+			cut = incompleteRuneStart(chunk)
+		}
+		c.carry = append([]byte(nil), chunk[cut:]...)
+		chunk = chunk[:cut]
+	}
+	if c.done && len(c.carry) > 0 {
+		chunk = append(chunk, c.carry...)
+		c.carry = nil
+	}
+	if len(chunk) > 0 {
+		c.endings.add(chunk)
+		c.read += int64(len(chunk))
+		c.last = chunk[len(chunk)-1]
+	}
+	return chunk, nil
+}
+
+// lines counts the lines returned so far the way countLinesIn does.
+func (c *chunkReader) lines() int {
+	return countedLines(&c.endings, c.read, c.last)
+}
+
+// countedLines is the number of lines in read bytes with the given line
+// endings, ending with last: a final line without an ending counts, and
+// empty input is one line.
+func countedLines(endings *lineEndingCounts, read int64, last byte) int {
+	lines := endings.total()
+	if read > 0 && last != '\n' && last != '\r' {
+		lines++
+	}
+	return max(lines, 1)
+}
+
+// Done reports whether the last chunk has been returned.
+func (c *chunkReader) Done() bool {
+	return c.done && len(c.carry) == 0
+}
+
+// incompleteRuneStart returns the index where a truncated UTF-8 sequence at
+// the end of b starts, or len(b) when b ends on a rune boundary.
+func incompleteRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if !utf8.FullRune(b[i:]) {
+			return i
+		}
+		break
+	}
+	return len(b)
+}
+
+// trackingWriter remembers how many bytes were written, the last one, and
+// the first write error.
+type trackingWriter struct {
+	w    io.Writer
+	n    int64
+	last byte
+	err  error
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if n > 0 {
+		t.n += int64(n)
+		t.last = p[n-1]
+	}
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
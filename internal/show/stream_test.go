@@ -0,0 +1,253 @@
+package show
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// streamReader only exposes Open, so RunShowTo cannot seek or size it.
+type streamReader struct {
+	data string
+}
+
+func (s streamReader) ReadFile(string) ([]byte, error) {
+	return []byte(s.data), nil
+}
+
+func (s streamReader) Open(string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(s.data)), nil
+}
+
+func setPlainEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+}
+
+// numberLines writes each of writes to a lineNumberer configured with g and
+// then setup, and returns what it printed.
+func numberLines(t *testing.T, g GutterOptions, setup func(*lineNumberer), writes ...string) string {
+	t.Helper()
+	var b strings.Builder
+	numbers := newLineNumberer(&b, 2)
+	if err := numbers.configure(g, false, DepthTrueColor); err != nil {
+		t.Fatal(err)
+	}
+	setup(numbers)
+	for _, s := range writes {
+		if _, err := numbers.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := numbers.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+func setChunkSize(t *testing.T, size int) {
+	t.Helper()
+	old := streamChunkSize
+	streamChunkSize = size
+	t.Cleanup(func() { streamChunkSize = old })
+}
+
+func TestRunShowToMatchesRunShow(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: stubReader{data: []byte("package main\n\nfunc main() {}\n")}}
+	opts := ShowOptions{Path: "main.go", Debug: true}
+	result, err := RunShow(t.Context(), deps, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := RunShowTo(t.Context(), deps, opts, &buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if buf.String() != string(result.Content) {
+		t.Fatalf("expected identical output\nRunShow:   %q\nRunShowTo: %q", result.Content, buf.String())
+	}
+}
+
+func TestRunShowToChunked(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 32)
+
+	var input strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&input, "line %d\n", i)
+	}
+
+	var buf bytes.Buffer
+	deps := Deps{FileReader: streamReader{data: input.String()}}
+	if err := RunShowTo(t.Context(), deps, ShowOptions{Path: "log.txt"}, &buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stripEscapes(buf.String()), "\n"), "\n")
+	if len(lines) != 40 {
+		t.Fatalf("expected 40 lines, got %d: %q", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		want := fmt.Sprintf("%*d | line %d", streamLineNumberWidth, i+1, i+1)
+		if line != want {
+			t.Fatalf("line %d: expected %q, got %q", i+1, want, line)
+		}
+	}
+}
+
+func TestRunShowToSeekableWidth(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 16)
+
+	input := strings.Repeat("x\n", 12)
+	var buf bytes.Buffer
+	if err := RunShowTo(t.Context(), Deps{FileReader: stubReader{data: []byte(input)}}, ShowOptions{Path: "a.txt"}, &buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := stripEscapes(buf.String())
+	if !strings.HasPrefix(got, " 1 | x\n") || !strings.Contains(got, "12 | x\n") {
+		t.Fatalf("expected two-digit gutter, got %q", buf.String())
+	}
+}
+
+// failingSource fails every read after it has been rewound, as a file that
+// hits an I/O error while its lines are counted would.
+type failingSource struct {
+	*bytes.Reader
+	rewound bool
+}
+
+func (f *failingSource) Read(p []byte) (int, error) {
+	if f.rewound {
+		return 0, errors.New("disk error")
+	}
+	return f.Reader.Read(p)
+}
+
+func (f *failingSource) Seek(offset int64, whence int) (int64, error) {
+	f.rewound = true
+	return f.Reader.Seek(offset, whence)
+}
+
+func (f *failingSource) Close() error {
+	return nil
+}
+
+type failingOpener struct{}
+
+func (failingOpener) ReadFile(string) ([]byte, error) {
+	return nil, errors.New("not used")
+}
+
+func (failingOpener) Open(string) (io.ReadCloser, error) {
+	return &failingSource{Reader: bytes.NewReader([]byte("a\nb\n"))}, nil
+}
+
+func TestRunShowToCountLinesError(t *testing.T) {
+	setPlainEnv(t)
+
+	var buf bytes.Buffer
+	err := RunShowTo(t.Context(), Deps{FileReader: failingOpener{}}, ShowOptions{Path: "a.txt"}, &buf)
+	if err == nil || err.Error() != "read file: disk error" {
+		t.Fatalf("expected read file error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestRunShowToLongLine(t *testing.T) {
+	setPlainEnv(t)
+	setChunkSize(t, 16)
+
+	long := strings.Repeat("é", 50)
+	var buf bytes.Buffer
+	deps := Deps{FileReader: streamReader{data: long + "\nend\n"}}
+	if err := RunShowTo(t.Context(), deps, ShowOptions{Path: "a.txt"}, &buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := fmt.Sprintf("%*d | %s\n%*d | end\n", streamLineNumberWidth, 1, long, streamLineNumberWidth, 2)
+	if stripEscapes(buf.String()) != want {
+		t.Fatalf("expected long line kept intact\nwant %q\ngot  %q", want, buf.String())
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	input := "ab\n" + strings.Repeat("界", 10) + "\ncd"
+	chunks := newChunkReader(strings.NewReader(input), 16)
+
+	var got []byte
+	for !chunks.Done() {
+		chunk, err := chunks.Next()
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !utf8.Valid(chunk) {
+			t.Fatalf("chunk split a UTF-8 sequence: %q", chunk)
+		}
+		got = append(got, chunk...)
+	}
+	if string(got) != input {
+		t.Fatalf("expected chunks to reassemble input, got %q", got)
+	}
+}
+
+func TestLineNumbererSplitWrites(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_CTYPE", "C")
+	t.Setenv("LANG", "C")
+
+	var buf bytes.Buffer
+	numbers := newLineNumberer(&buf, 1)
+	for _, piece := range []string{"\x1b[31m", "a", "\x1b[0m\n", "\x1b[32m", "b\n", "\x1b[0m"} {
+		if _, err := numbers.Write([]byte(piece)); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	}
+	if err := numbers.Flush(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := "\x1b[0m\x1b[37m1 |\x1b[0m \x1b[31ma\x1b[0m\n" +
+		"\x1b[0m\x1b[37m2 |\x1b[0m \x1b[32mb\n" +
+		"\x1b[0m"
+	if buf.String() != want {
+		t.Fatalf("expected colour after prefix\nwant %q\ngot  %q", want, buf.String())
+	}
+}
+
+func TestRunShowFinalLineWithoutNewline(t *testing.T) {
+	setPlainEnv(t)
+
+	deps := Deps{FileReader: stubReader{data: []byte("a\nb")}}
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Color: ColorNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got := string(result.Content); got != "1 | a\n2 | b" {
+		t.Fatalf("expected both lines numbered, got %q", got)
+	}
+}
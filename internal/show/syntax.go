@@ -0,0 +1,75 @@
+package show
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SyntaxMapping assigns FileType to paths matching Pattern.
+type SyntaxMapping struct {
+	// Pattern is a glob as understood by path.Match. Patterns without a
+	// slash match the base name, so "*.tpl" and "Jenkinsfile.*" work in any
+	// directory; patterns with a slash match the trailing path components.
+	// Matching ignores case.
+	Pattern  string
+	FileType string
+}
+
+// ParseSyntaxMapping parses "GLOB:TYPE". The type follows the last colon,
+// so globs may contain colons themselves.
+func ParseSyntaxMapping(value string) (SyntaxMapping, error) {
+	i := strings.LastIndex(value, ":")
+	if i <= 0 || i == len(value)-1 {
+		return SyntaxMapping{}, fmt.Errorf("invalid syntax mapping %q (want GLOB:TYPE)", value)
+	}
+	m := SyntaxMapping{Pattern: strings.TrimSpace(value[:i]), FileType: strings.TrimSpace(value[i+1:])}
+	if _, err := path.Match(m.Pattern, ""); err != nil {
+		return SyntaxMapping{}, fmt.Errorf("invalid syntax mapping %q: bad glob", value)
+	}
+	if !IsSupportedFileType(m.FileType) {
+		return SyntaxMapping{}, fmt.Errorf("invalid syntax mapping %q: unknown file type %s", value, m.FileType)
+	}
+	return m, nil
+}
+
+// ExtensionMapping maps files ending in ext, with or without its leading
+// dot, to fileType.
+func ExtensionMapping(ext string, fileType string) SyntaxMapping {
+	return SyntaxMapping{Pattern: "*." + strings.TrimPrefix(ext, "."), FileType: fileType}
+}
+
+// Match reports whether p matches Pattern.
+func (m SyntaxMapping) Match(p string) bool {
+	pattern := strings.ToLower(m.Pattern)
+	name := strings.ToLower(filepath.ToSlash(p))
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	for {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		i := strings.Index(name, "/")
+		if i < 0 {
+			return false
+		}
+		name = name[i+1:]
+	}
+}
+
+// mappedFileType returns the file type of the first mapping matching path,
+// or "" when none does.
+func mappedFileType(p string, mappings []SyntaxMapping) string {
+	if p == StdinPath {
+		return ""
+	}
+	for _, m := range mappings {
+		if m.Match(p) {
+			return m.FileType
+		}
+	}
+	return ""
+}
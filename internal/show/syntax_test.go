@@ -0,0 +1,89 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSyntaxMapping(t *testing.T) {
+	m, err := ParseSyntaxMapping("*.conf.j2:jinja")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if m.Pattern != "*.conf.j2" || m.FileType != "jinja" {
+		t.Fatalf("unexpected mapping: %+v", m)
+	}
+
+	m, err = ParseSyntaxMapping(`C:\*.tpl:go`)
+	if err != nil || m.Pattern != `C:\*.tpl` || m.FileType != "go" {
+		t.Fatalf("expected type after the last colon, got %+v, %v", m, err)
+	}
+
+	for _, value := range []string{"*.tpl", ":go", "*.tpl:", "[:go", "*.tpl:nope"} {
+		if _, err := ParseSyntaxMapping(value); err == nil {
+			t.Fatalf("expected error for %q", value)
+		}
+	}
+}
+
+func TestSyntaxMappingMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.tpl", "templates/page.tpl", true},
+		{"*.tpl", "page.TPL", true},
+		{"Jenkinsfile.*", "ci/Jenkinsfile.release", true},
+		{"Jenkinsfile.*", "Jenkinsfile", false},
+		{"*.conf.j2", "/etc/nginx.conf.j2", true},
+		{"bin/*", "repo/bin/deploy", true},
+		{"bin/*", "repo/sbin/deploy", false},
+		{"bin/*", "bin/sub/deploy", false},
+	}
+	for _, tt := range tests {
+		m := SyntaxMapping{Pattern: tt.pattern, FileType: "go"}
+		if got := m.Match(tt.path); got != tt.want {
+			t.Fatalf("%q.Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMappedFileType(t *testing.T) {
+	mappings := []SyntaxMapping{
+		{Pattern: "*.tpl", FileType: "go"},
+		{Pattern: "*.tpl", FileType: "html"},
+		ExtensionMapping(".j2", "jinja"),
+	}
+	if got := mappedFileType("page.tpl", mappings); got != "go" {
+		t.Fatalf("expected first mapping to win, got %q", got)
+	}
+	if got := mappedFileType("nginx.J2", mappings); got != "jinja" {
+		t.Fatalf("expected extension mapping, got %q", got)
+	}
+	if got := mappedFileType(StdinPath, []SyntaxMapping{{Pattern: "*", FileType: "go"}}); got != "" {
+		t.Fatalf("expected no mapping for stdin, got %q", got)
+	}
+}
+
+func TestRunShowSyntaxMappingDebug(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	opts := ShowOptions{
+		Path:           "deploy/Jenkinsfile.release",
+		Debug:          true,
+		Header:         true,
+		SyntaxMappings: []SyntaxMapping{{Pattern: "Jenkinsfile.*", FileType: "groovy"}},
+	}
+	result, err := RunShow(t.Context(), Deps{FileReader: stubReader{data: []byte("pipeline {}\n")}}, opts)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	output := string(result.Content)
+	if strings.Count(output, "DEBUG file type: Groovy") != 2 {
+		t.Fatalf("expected mapped type in debug output, got %q", output)
+	}
+	if !strings.Contains(output, "(Groovy,") {
+		t.Fatalf("expected mapped type in header, got %q", output)
+	}
+}
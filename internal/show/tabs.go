@@ -0,0 +1,44 @@
+package show
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func validateTabs(width int) error {
+	if width < 0 {
+		return fmt.Errorf("invalid tab width: %d", width)
+	}
+	return nil
+}
+
+// expandTabs replaces each tab in s with spaces up to the next multiple of
+// n.tabs, counted from the start of the content column rather than the
+// start of the terminal line. Escape sequences take no columns.
+func (n *lineNumberer) expandTabs(s string) string {
+	if n.tabs == 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			b.WriteString(s[i : i+l])
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == '\t' {
+			spaces := n.tabs - n.tabCol%n.tabs
+			b.WriteString(strings.Repeat(" ", spaces))
+			n.tabCol += spaces
+		} else {
+			b.WriteString(s[i : i+size])
+			n.tabCol += runewidth.RuneWidth(r)
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func tabWidth(tabs int) func(*lineNumberer) {
+	return func(n *lineNumberer) { n.tabs = tabs }
+}
+
+func TestExpandTabs(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{}, tabWidth(4), "\tx\nab\tc\n")
+	if want := " 1 | " + "    x\n 2 | ab  c\n"; got != want {
+		t.Fatalf("expected tab stops counted from the content column, got %q", got)
+	}
+
+	got = numberLines(t, GutterOptions{}, tabWidth(4), "a\x1b[31m\tb\x1b[0m\t", "c\n")
+	if want := " 1 | a\x1b[31m   b\x1b[0m   c\n"; got != want {
+		t.Fatalf("expected escapes to be kept and take no columns, got %q", got)
+	}
+
+	got = numberLines(t, GutterOptions{Hide: true}, tabWidth(2), "你\tx\n")
+	if want := "你  x\n"; got != want {
+		t.Fatalf("expected wide runes to take two columns, got %q", got)
+	}
+
+	got = numberLines(t, GutterOptions{}, tabWidth(0), "\tx\n")
+	if want := " 1 | \tx\n"; got != want {
+		t.Fatalf("expected tabs to pass through, got %q", got)
+	}
+}
+
+func TestRunShowTabs(t *testing.T) {
+	setPlainEnv(t)
+	deps := Deps{FileReader: stubReader{data: []byte("a:\n\tb\n")}}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "Makefile", Tabs: 4})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got := stripEscapes(string(result.Content)); strings.Contains(got, "\t") || !strings.Contains(got, "2 |     b") {
+		t.Fatalf("expected tabs expanded to 4 columns, got %q", got)
+	}
+
+	result, err = RunShow(t.Context(), deps, ShowOptions{Path: "Makefile", Tabs: 4, Color: ColorNever, Decorations: DecorationsNever})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if string(result.Content) != "a:\n\tb\n" {
+		t.Fatalf("expected undecorated output to be copied through, got %q", result.Content)
+	}
+
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "Makefile", Tabs: -1}); err == nil || !strings.Contains(err.Error(), "invalid tab width") {
+		t.Fatalf("expected invalid tab width error, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package show
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Wrap modes. WrapAuto wraps at character boundaries when writing to a
+// terminal of known width; WrapWord breaks at spaces where it can.
+const (
+	WrapAuto      = "auto"
+	WrapNever     = "never"
+	WrapCharacter = "character"
+	WrapWord      = "word"
+)
+
+// defaultTerminalWidth is used when wrapping is forced but the output width
+// is unknown.
+const defaultTerminalWidth = 80
+
+// terminalTabStop is the tab stop terminals use when tabs are printed as is.
+const terminalTabStop = 8
+
+func validateWrap(mode string, width int) error {
+	switch mode {
+	case "", WrapAuto, WrapNever, WrapCharacter, WrapWord:
+	default:
+		return fmt.Errorf("unknown wrap mode: %s (want auto, never, character or word)", mode)
+	}
+	if width < 0 {
+		return fmt.Errorf("invalid terminal width: %d", width)
+	}
+	return nil
+}
+
+// wrapMode resolves mode to WrapNever, WrapCharacter or WrapWord and
+// returns the total width to wrap at.
+func wrapMode(mode string, width int, terminal bool) (string, int) {
+	switch mode {
+	case WrapCharacter, WrapWord:
+		if width == 0 {
+			width = defaultTerminalWidth
+		}
+		return mode, width
+	case "", WrapAuto:
+		if terminal && width > 0 {
+			return WrapCharacter, width
+		}
+	}
+	return WrapNever, 0
+}
+
+// configureWrap wraps content to total columns, less the gutter.
+func (n *lineNumberer) configureWrap(mode string, total int) {
+	if mode == WrapNever || total == 0 {
+		return
+	}
+	n.wrap = mode
+	if !n.hide {
+		n.prefixWidth = n.width + 1 + runewidth.StringWidth(n.sep) + 1
+		if n.changes != nil {
+			n.prefixWidth += 2
+		}
+	}
+	n.limit = max(total-n.prefixWidth, 1)
+}
+
+// wrapText writes part of a line, breaking it whenever the next rune or, in
+// word mode, the next word would pass the wrap limit. Escape sequences take
+// no columns and SGR sequences are replayed after each break.
+func (n *lineNumberer) wrapText(s string) error {
+	var text strings.Builder
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			seq := s[i : i+l]
+			i += l
+			if n.wrap == WrapWord && n.word.Len() > 0 {
+				n.word.WriteString(seq)
+				continue
+			}
+			n.trackEscape(seq)
+			text.WriteString(seq)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		glyph := s[i : i+size]
+		i += size
+
+		if n.wrap == WrapWord && !n.longWord && r != ' ' && r != '\t' {
+			n.word.WriteString(glyph)
+			n.wordWidth += runewidth.RuneWidth(r)
+			if n.wordWidth <= n.limit {
+				continue
+			}
+			// The word cannot fit on any line, so break it by character.
+			n.longWord = true
+			if err := n.flushWord(&text); err != nil {
+				return err
+			}
+			continue
+		}
+		if r == ' ' || r == '\t' {
+			n.longWord = false
+			if err := n.flushWord(&text); err != nil {
+				return err
+			}
+		}
+		if err := n.wrapRune(&text, r, glyph); err != nil {
+			return err
+		}
+	}
+	return n.emit(text.String())
+}
+
+// wrapRune adds one rune to text, breaking the line first if it would not
+// fit.
+func (n *lineNumberer) wrapRune(text *strings.Builder, r rune, glyph string) error {
+	w := n.runeWidth(r)
+	if n.col > 0 && n.col+w > n.limit {
+		if err := n.emit(text.String()); err != nil {
+			return err
+		}
+		text.Reset()
+		if err := n.breakLine(); err != nil {
+			return err
+		}
+		if r == ' ' || (r == '\t' && n.wrap == WrapWord) {
+			return nil
+		}
+		w = n.runeWidth(r)
+	}
+	text.WriteString(glyph)
+	n.col += w
+	return nil
+}
+
+// flushWord writes the word held back in word mode, moving it to a new line
+// first when it does not fit on the current one.
+func (n *lineNumberer) flushWord(text *strings.Builder) error {
+	if n.word.Len() == 0 {
+		return nil
+	}
+	word := n.word.String()
+	width := n.wordWidth
+	n.word.Reset()
+	n.wordWidth = 0
+	if n.col > 0 && n.col+width > n.limit {
+		if err := n.emit(text.String()); err != nil {
+			return err
+		}
+		text.Reset()
+		if err := n.breakLine(); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < len(word); {
+		if l := escapeLen(word[i:]); l > 0 {
+			n.trackEscape(word[i : i+l])
+			text.WriteString(word[i : i+l])
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word[i:])
+		if err := n.wrapRune(text, r, word[i:i+size]); err != nil {
+			return err
+		}
+		i += size
+	}
+	return nil
+}
+
+// finishWrap flushes any held back word at the end of a line.
+func (n *lineNumberer) finishWrap() error {
+	if n.limit == 0 {
+		return nil
+	}
+	var text strings.Builder
+	if err := n.flushWord(&text); err != nil {
+		return err
+	}
+	n.longWord = false
+	return n.emit(text.String())
+}
+
+// breakLine ends a visual line and starts a continuation line with a blank
+// gutter, restoring the line highlight and the active colours.
+func (n *lineNumberer) breakLine() error {
+	end := "\n"
+	if n.marked && n.highlightBG != "" {
+		end = "\x1b[K\x1b[0m\n"
+	} else if n.sgr.Len() > 0 {
+		end = "\x1b[0m\n"
+	}
+	if _, err := io.WriteString(n.w, end); err != nil {
+		return err
+	}
+	if !n.hide {
+		blank := n.width
+		if n.changes != nil {
+			blank += 2
+		}
+		var err error
+		if n.color != "" {
+			_, err = fmt.Fprintf(n.w, "\x1b[0m%s%*s %s\x1b[0m ", n.color, blank, "", n.sep)
+		} else {
+			_, err = fmt.Fprintf(n.w, "%*s %s ", blank, "", n.sep)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if n.marked && n.highlightBG != "" {
+		if _, err := io.WriteString(n.w, n.highlightBG); err != nil {
+			return err
+		}
+	}
+	n.col = 0
+	if n.sgr.Len() > 0 {
+		return n.emit(n.sgr.String())
+	}
+	return nil
+}
+
+// trackEscapes remembers the SGR sequences in s, which is written or skipped
+// without wrapping.
+func (n *lineNumberer) trackEscapes(s string) {
+	for i := 0; i < len(s); i++ {
+		if l := escapeLen(s[i:]); l > 0 {
+			n.trackEscape(s[i : i+l])
+			i += l - 1
+		}
+	}
+}
+
+// trackEscape remembers the SGR sequences in effect since the last reset.
+func (n *lineNumberer) trackEscape(seq string) {
+	switch {
+	case isReset(seq):
+		n.sgr.Reset()
+	case strings.HasSuffix(seq, "m"):
+		n.sgr.WriteString(seq)
+	}
+}
+
+// runeWidth is the number of columns r takes at the current column. Tabs
+// advance to the terminal's next tab stop, which counts the gutter.
+func (n *lineNumberer) runeWidth(r rune) int {
+	if r == '\t' {
+		return terminalTabStop - (n.prefixWidth+n.col)%terminalTabStop
+	}
+	return runewidth.RuneWidth(r)
+}
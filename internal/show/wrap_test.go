@@ -0,0 +1,113 @@
+package show
+
+import (
+	"strings"
+	"testing"
+)
+
+func wrapTo(mode string, total int) func(*lineNumberer) {
+	return func(n *lineNumberer) { n.configureWrap(mode, total) }
+}
+
+func TestWrapCharacter(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{}, wrapTo(WrapCharacter, 12), "abcdefghijkl\nshort\n")
+	want := " 1 | abcdefg\n   | hijkl\n 2 | short\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWrapWord(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{}, wrapTo(WrapWord, 12), "aaa bbb ccc dddddddddddd\n")
+	want := " 1 | aaa bbb\n   | ccc \n   | ddddddd\n   | ddddd\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWrapWideRunesAndTabs(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{}, wrapTo(WrapCharacter, 10), "你好世界\n")
+	if want := " 1 | 你好\n   | 世界\n"; got != want {
+		t.Fatalf("expected wide runes to take two columns, got %q", got)
+	}
+
+	// The gutter is 5 columns, so the tab reaches column 8 and takes 3.
+	got = numberLines(t, GutterOptions{}, wrapTo(WrapCharacter, 12), "\tabcdx\n")
+	if want := " 1 | \tabcd\n   | x\n"; got != want {
+		t.Fatalf("expected tab to advance to the next tab stop, got %q", got)
+	}
+}
+
+func TestWrapWithoutGutter(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{Hide: true}, wrapTo(WrapCharacter, 4), "abcdefghij")
+	if want := "abcd\nefgh\nij"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWrapReplaysColours(t *testing.T) {
+	setPlainEnv(t)
+
+	got := numberLines(t, GutterOptions{}, wrapTo(WrapCharacter, 9), "\x1b[31mabcdef\x1b[0m\n")
+	want := " 1 | \x1b[31mabcd\x1b[0m\n   | \x1b[31mef\x1b[0m\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunShowWrap(t *testing.T) {
+	setPlainEnv(t)
+	deps := Deps{FileReader: stubReader{data: []byte(strings.Repeat("x", 30) + "\n")}}
+
+	result, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Wrap: WrapCharacter, TerminalWidth: 20})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(stripEscapes(string(result.Content)), "\n"), "\n") {
+		if len(line) > 20 {
+			t.Fatalf("expected lines of at most 20 columns, got %q", line)
+		}
+	}
+
+	result, err = RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", TerminalWidth: 20})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Count(string(result.Content), "\n") != 1 {
+		t.Fatalf("expected auto wrap to do nothing off a terminal, got %q", result.Content)
+	}
+
+	if _, err := RunShow(t.Context(), deps, ShowOptions{Path: "a.txt", Wrap: "sometimes"}); err == nil || !strings.Contains(err.Error(), "unknown wrap mode") {
+		t.Fatalf("expected unknown wrap mode error, got %v", err)
+	}
+}
+
+func TestWrapMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		width    int
+		terminal bool
+		want     string
+		total    int
+	}{
+		{"", 100, true, WrapCharacter, 100},
+		{WrapAuto, 0, true, WrapNever, 0},
+		{WrapAuto, 100, false, WrapNever, 0},
+		{WrapNever, 100, true, WrapNever, 0},
+		{WrapWord, 0, false, WrapWord, defaultTerminalWidth},
+	}
+	for _, tt := range tests {
+		mode, total := wrapMode(tt.mode, tt.width, tt.terminal)
+		if mode != tt.want || total != tt.total {
+			t.Fatalf("wrapMode(%q, %d, %v) = %s, %d; want %s, %d", tt.mode, tt.width, tt.terminal, mode, total, tt.want, tt.total)
+		}
+	}
+}